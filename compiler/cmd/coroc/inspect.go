@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/stealthrocket/coroutine/types"
+)
+
+const inspectUsage = `
+coroc inspect prints a summary of serialized durable coroutine state.
+
+USAGE:
+  coroc inspect [OPTIONS] [FILE]
+
+The state is read from FILE, or from stdin if FILE is omitted or is -.
+
+OPTIONS:
+  -h, --help   Show this help information
+  -json        Output the state as JSON
+`
+
+func inspect(args []string) error {
+	flags := flag.NewFlagSet("inspect", flag.ExitOnError)
+	flags.Usage = func() { println(inspectUsage[1:]) }
+
+	var asJSON bool
+	flags.BoolVar(&asJSON, "json", false, "")
+
+	flags.Parse(args)
+
+	var b []byte
+	var err error
+	switch path := flags.Arg(0); path {
+	case "", "-":
+		b, err = io.ReadAll(os.Stdin)
+	default:
+		b, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	state, err := types.Inspect(b)
+	if err != nil {
+		return fmt.Errorf("cannot inspect state: %w", err)
+	}
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(state)
+	}
+	return printSummary(os.Stdout, state)
+}
+
+func printSummary(w io.Writer, state *types.State) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "build:\t%s\n", state.BuildID())
+	fmt.Fprintf(tw, "platform:\t%s/%s\n", state.OS(), state.Arch())
+	if v := state.GoVersion(); v != "" {
+		fmt.Fprintf(tw, "go version:\t%s\n", v)
+	}
+	if recv, send := state.YieldTypes(); recv != nil {
+		fmt.Fprintf(tw, "yield types:\t%v, %v\n", recv, send)
+	}
+	root := state.Root()
+	fmt.Fprintf(tw, "root:\t%v (%d byte(s))\n", root.Type(), root.Size())
+	fmt.Fprintf(tw, "types:\t%d\n", state.NumType())
+
+	fmt.Fprintf(tw, "functions:\t%d\n", state.NumFunction())
+	for i := 0; i < state.NumFunction(); i++ {
+		f := state.Function(i)
+		if ct := f.ClosureType(); ct != nil {
+			fmt.Fprintf(tw, "\t  %s\t(closure)\n", f)
+		} else {
+			fmt.Fprintf(tw, "\t  %s\t\n", f)
+		}
+	}
+
+	stats := state.Stats()
+	fmt.Fprintf(tw, "regions:\t%d (%d byte(s))\n", stats.Regions, stats.RegionBytes)
+	for i := 0; i < state.NumRegion(); i++ {
+		r := state.Region(i)
+		fmt.Fprintf(tw, "\t  #%d\t%v\t%d byte(s)\n", r.Index(), r.Type(), r.Size())
+	}
+	return tw.Flush()
+}
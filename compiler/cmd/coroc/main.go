@@ -14,9 +14,14 @@ coroc is a coroutine compiler for Go.
 
 USAGE:
   coroc [OPTIONS] [PATH]
+  coroc inspect [OPTIONS] [FILE]
+
+COMMANDS:
+  inspect         Print a summary of serialized coroutine state
 
 OPTIONS:
   -h, --help      Show this help information
+  -n, --dry-run   List coroutines and generated files without writing them
   -v, --version   Show the compiler version
 `
 
@@ -28,12 +33,20 @@ func main() {
 }
 
 func run() error {
+	if len(os.Args) > 1 && os.Args[1] == "inspect" {
+		return inspect(os.Args[2:])
+	}
+
 	flag.Usage = func() { println(usage[1:]) }
 
 	var showVersion bool
 	flag.BoolVar(&showVersion, "v", false, "")
 	flag.BoolVar(&showVersion, "version", false, "")
 
+	var dryRun bool
+	flag.BoolVar(&dryRun, "n", false, "")
+	flag.BoolVar(&dryRun, "dry-run", false, "")
+
 	flag.Parse()
 
 	if showVersion {
@@ -55,7 +68,11 @@ func run() error {
 		}
 	}
 
-	return compiler.Compile(path)
+	var options []compiler.Option
+	if dryRun {
+		options = append(options, compiler.WithDryRun(os.Stdout))
+	}
+	return compiler.Compile(path, options...)
 }
 
 func version() (version string) {
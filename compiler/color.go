@@ -37,7 +37,8 @@ func colorFunctions0(cg *callgraph.Graph, colors functionColors, fn *ssa.Functio
 	existing, ok := colors[fn]
 	if ok {
 		if !types.Identical(existing, color) {
-			return fmt.Errorf("function %s has more than one color (%v + %v)", fn, existing, color)
+			return fmt.Errorf("%s: function %s has more than one color: it is part of coroutines with different yield types (%s + %s)",
+				fn.Prog.Fset.Position(fn.Pos()), fn, yieldTypes(existing), yieldTypes(color))
 		}
 		return nil // already walked
 	}
@@ -49,3 +50,9 @@ func colorFunctions0(cg *callgraph.Graph, colors functionColors, fn *ssa.Functio
 	}
 	return nil
 }
+
+// yieldTypes formats the R and S types of a color (the signature of an
+// instance of coroutine.Yield) as type arguments, e.g. [int, any].
+func yieldTypes(color *types.Signature) string {
+	return fmt.Sprintf("[%v, %v]", color.Params().At(0).Type(), color.Results().At(0).Type())
+}
@@ -1,12 +1,15 @@
 package compiler
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/build/constraint"
 	"go/format"
 	"go/token"
 	"go/types"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -21,6 +24,7 @@ import (
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
+	"golang.org/x/tools/imports"
 )
 
 const coroutinePackage = "github.com/stealthrocket/coroutine"
@@ -33,6 +37,11 @@ const coroutinePackage = "github.com/stealthrocket/coroutine"
 // nearest module is located and compiled as a whole.
 //
 // The path can be absolute, or relative to the current working directory.
+//
+// For each source file of a package declaring coroutines, a file with the
+// _durable.go suffix is generated next to it and tagged //go:build durable.
+// The build tags of the source file are amended with !durable, so the
+// package compiles both with and without -tags durable.
 func Compile(path string, options ...Option) error {
 	c := &compiler{
 		fset: token.NewFileSet(),
@@ -43,13 +52,66 @@ func Compile(path string, options ...Option) error {
 	return c.compile(path)
 }
 
+// CompileToMap is like Compile, but rather than writing files to the
+// filesystem it returns their content in a map keyed by file path.
+//
+// The map holds both the generated *_durable.go files and the input files
+// with their build tags amended to exclude them from durable builds, which
+// makes it suitable for use as a build overlay (see go help build).
+//
+// An error is returned if compiling the module would require vendoring
+// packages from GOROOT, since it cannot be done without writing to the
+// filesystem.
+func CompileToMap(path string, options ...Option) (map[string][]byte, error) {
+	c := &compiler{
+		fset:   token.NewFileSet(),
+		output: map[string][]byte{},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if err := c.compile(path); err != nil {
+		return nil, err
+	}
+	return c.output, nil
+}
+
 // Option configures the compiler.
 type Option func(*compiler)
 
+// WithDryRun makes the compiler report the functions that were colored as
+// coroutines, and the files that would be generated for each package,
+// instead of writing anything to disk. The report is written to w.
+//
+// This is useful to verify that build tags and instantiations of
+// coroutine.Yield are picked up as expected.
+func WithDryRun(w io.Writer) Option {
+	return func(c *compiler) { c.dryRun = w }
+}
+
+// WithVerify makes the compiler type-check the durable build of the
+// packages it generated code for before writing any file. An error in the
+// generated code is a bug in the compiler; it is reported as such, and the
+// files of the module are left untouched.
+func WithVerify() Option {
+	return func(c *compiler) { c.verify = true }
+}
+
 type compiler struct {
 	coroutinePkg *packages.Package
 
 	fset *token.FileSet
+
+	// When non-nil, files are written to the map instead of the filesystem.
+	output map[string][]byte
+
+	// When non-nil, a report of the compilation is written instead of
+	// any file (see WithDryRun).
+	dryRun io.Writer
+
+	// When true, generated code is type-checked before it is written
+	// (see WithVerify).
+	verify bool
 }
 
 func (c *compiler) compile(path string) error {
@@ -150,6 +212,18 @@ func (c *compiler) compile(path string) error {
 	colorsByPkg := map[*packages.Package]functionColors{}
 	for fn, color := range colors {
 		if fn.Pkg == nil {
+			if fn.Synthetic != "" && fn.Syntax() == nil {
+				// Wrappers synthesized for promoted methods, or for methods
+				// with a value receiver called through a pointer, don't need
+				// to be compiled: they only call the method, which is.
+				continue
+			}
+			if origin := fn.Origin(); origin != nil {
+				// Instances of a generic function share its syntax, which
+				// may be part of coroutines with different yield types.
+				return fmt.Errorf("%s: not implemented: generic function %s in coroutines (instantiated as %s)",
+					c.fset.Position(origin.Pos()), origin, fn)
+			}
 			return fmt.Errorf("unsupported yield function %s (Pkg is nil)", fn)
 		}
 
@@ -162,12 +236,35 @@ func (c *compiler) compile(path string) error {
 		pkgColors[fn] = color
 	}
 
+	// Packages are processed in a stable order, so the errors, logs and
+	// output of the compiler are the same from one run to the next.
+	colorPkgs := make([]*packages.Package, 0, len(colorsByPkg))
+	for p := range colorsByPkg {
+		colorPkgs = append(colorPkgs, p)
+	}
+	slices.SortFunc(colorPkgs, func(a, b *packages.Package) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	})
+
+	// Reject certain language features for now. All the packages are
+	// checked before any is compiled, so every unsupported feature can
+	// be reported at once.
+	var unsupportedErrs []error
+	for _, p := range colorPkgs {
+		if err := checkUnsupported(p, colorsByPkg[p]); err != nil {
+			unsupportedErrs = append(unsupportedErrs, err)
+		}
+	}
+	if err := errors.Join(unsupportedErrs...); err != nil {
+		return err
+	}
+
 	// Before mutating packages, we need to ensure that packages exist in a
 	// location where mutations can be made safely (without affecting other
 	// builds).
 	var needVendoring []*packages.Package
 	goroot := runtime.GOROOT()
-	for p := range colorsByPkg {
+	for _, p := range colorPkgs {
 		dir := packageDir(p)
 
 		// The input module can be mutated, and so can nested
@@ -198,7 +295,13 @@ func (c *compiler) compile(path string) error {
 		// Reject packages outside ./vendor.
 		return fmt.Errorf("cannot mutate package %s (%s) safely. Please vendor dependencies: go mod vendor", p.PkgPath, dir)
 	}
+	if c.dryRun != nil {
+		return writeDryRun(c.dryRun, colorPkgs, colorsByPkg, needVendoring)
+	}
 	if len(needVendoring) > 0 {
+		if c.output != nil {
+			return fmt.Errorf("cannot vendor GOROOT packages when compiling to memory (%s)", needVendoring[0].PkgPath)
+		}
 		log.Printf("vendoring GOROOT packages")
 		newRoot := filepath.Join(moduleDir, "goroot")
 		if err := vendorGOROOT(newRoot, needVendoring); err != nil {
@@ -206,10 +309,37 @@ func (c *compiler) compile(path string) error {
 		}
 	}
 
-	for p, colors := range colorsByPkg {
-		if err := c.compilePackage(p, colors); err != nil {
+	if !c.verify {
+		for _, p := range colorPkgs {
+			if err := c.compilePackage(p, colorsByPkg[p]); err != nil {
+				return err
+			}
+		}
+	} else {
+		// Buffer the files until the generated code has been verified.
+		output := c.output
+		c.output = map[string][]byte{}
+		for _, p := range colorPkgs {
+			if err := c.compilePackage(p, colorsByPkg[p]); err != nil {
+				return err
+			}
+		}
+		files := c.output
+		c.output = output
+
+		if err := verify(absPath, pattern, colorPkgs, files); err != nil {
 			return err
 		}
+		paths := make([]string, 0, len(files))
+		for path := range files {
+			paths = append(paths, path)
+		}
+		slices.Sort(paths)
+		for _, path := range paths {
+			if err := c.writeOutput(path, files[path]); err != nil {
+				return err
+			}
+		}
 	}
 
 	log.Printf("done")
@@ -217,36 +347,65 @@ func (c *compiler) compile(path string) error {
 }
 
 func (c *compiler) writeFile(path string, file *ast.File, changeBuildTags func(constraint.Expr) constraint.Expr) error {
-	buildTags, err := parseBuildTags(file)
+	b, err := c.formatFile(path, file, changeBuildTags, "")
 	if err != nil {
 		return err
 	}
+	return c.writeOutput(path, b)
+}
+
+func (c *compiler) writeOutput(path string, b []byte) error {
+	if c.output != nil {
+		c.output[path] = b
+		return nil
+	}
+	return os.WriteFile(path, b, 0666)
+}
+
+// formatFile formats the file, preceded by its build tags (as amended by
+// changeBuildTags) and the header comment, if not empty.
+func (c *compiler) formatFile(path string, file *ast.File, changeBuildTags func(constraint.Expr) constraint.Expr, header string) ([]byte, error) {
+	buildTags, err := parseBuildTags(file)
+	if err != nil {
+		return nil, err
+	}
 	buildTags = changeBuildTags(buildTags)
 	stripBuildTagsOf(file, path)
 
 	// Comments are awkward to attach to the tree (they rely on token.Pos, which
 	// is coupled to a token.FileSet). Instead, just write out the raw strings.
-	var b strings.Builder
+	var b bytes.Buffer
 	if buildTags != nil {
 		b.WriteString(`//go:build `)
 		b.WriteString(buildTags.String())
 		b.WriteString("\n\n")
 	}
-
-	f, err := os.Create(path)
-	if err != nil {
-		return err
+	if header != "" {
+		b.WriteString(header)
+		b.WriteString("\n")
 	}
-	defer f.Close()
 
-	if _, err := f.WriteString(b.String()); err != nil {
-		return err
-	}
 	// Format/write the remainder of the AST.
-	if err := format.Node(f, c.fset, file); err != nil {
-		return err
+	if err := format.Node(&b, c.fset, file); err != nil {
+		return nil, err
 	}
-	return f.Close()
+	return b.Bytes(), nil
+}
+
+// formatImports sorts and groups the imports of a generated file the way
+// goimports does (standard library packages first, then third-party
+// packages).
+//
+// Generated files don't carry position information that could be used to
+// group and sort imports in the AST, so the pass is applied to the output
+// of the formatter instead.
+func formatImports(path string, src []byte) ([]byte, error) {
+	return imports.Process(path, src, &imports.Options{
+		Comments:   true,
+		TabIndent:  true,
+		TabWidth:   8,
+		FormatOnly: true,
+	})
 }
 
 func (c *compiler) compilePackage(p *packages.Package, colors functionColors) error {
@@ -296,9 +455,9 @@ func (c *compiler) compilePackage(p *packages.Package, colors functionColors) er
 					gen.Decls = append(gen.Decls, decl)
 					continue
 				}
-				// Reject certain language features for now.
-				if err := unsupported(decl, p.TypesInfo); err != nil {
-					return err
+				for _, ident := range loopVarCaptures(decl.Body, p.TypesInfo) {
+					log.Printf("warning: %s: function literal captures loop variable %s, which is shared by all iterations of the loop in coroutines",
+						p.Fset.Position(ident.Pos()), ident.Name)
 				}
 
 				scope := &scope{compiler: c, colors: colorsByFunc}
@@ -314,9 +473,17 @@ func (c *compiler) compilePackage(p *packages.Package, colors functionColors) er
 		outputPath := strings.TrimSuffix(p.GoFiles[i], ".go")
 		outputPath += "_durable.go"
 
-		if err := c.writeFile(outputPath, gen, func(expr constraint.Expr) constraint.Expr {
+		b, err := c.formatFile(outputPath, gen, func(expr constraint.Expr) constraint.Expr {
 			return withBuildTag(expr, buildTag)
-		}); err != nil {
+		}, generatedHeader(filepath.Base(p.GoFiles[i])))
+		if err != nil {
+			return err
+		}
+		b, err = formatImports(outputPath, b)
+		if err != nil {
+			return err
+		}
+		if err := c.writeOutput(outputPath, b); err != nil {
 			return err
 		}
 	}
@@ -324,6 +491,17 @@ func (c *compiler) compilePackage(p *packages.Package, colors functionColors) er
 	return nil
 }
 
+// generatedHeader returns the header comment of the file generated from
+// the source file with the given name.
+func generatedHeader(source string) string {
+	return `// Code generated by coroc. DO NOT EDIT.
+//
+// This file holds the durable version of ` + source + `. It is only built
+// with -tags durable, and the original source file is only built without
+// it (//go:build !durable), so the package compiles in both modes.
+`
+}
+
 func addImports(p *packages.Package, gen *ast.File) *ast.File {
 	imports := map[string]string{}
 
@@ -357,17 +535,32 @@ func addImports(p *packages.Package, gen *ast.File) *ast.File {
 		return true
 	})
 
-	if len(imports) == 0 {
+	// Imports that were added while generating the file (e.g. by
+	// generateFunctypes) are merged into the same declaration.
+	var importspecs []ast.Spec
+	decls := gen.Decls[:0]
+	for _, decl := range gen.Decls {
+		if g, ok := decl.(*ast.GenDecl); ok && g.Tok == token.IMPORT {
+			importspecs = append(importspecs, g.Specs...)
+		} else {
+			decls = append(decls, decl)
+		}
+	}
+	gen.Decls = decls
+
+	if len(imports) == 0 && len(importspecs) == 0 {
 		return gen
 	}
 
-	importspecs := make([]ast.Spec, 0, len(imports))
 	for name, path := range imports {
 		importspecs = append(importspecs, &ast.ImportSpec{
 			Name: ast.NewIdent(name),
 			Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)},
 		})
 	}
+	slices.SortFunc(importspecs, func(a, b ast.Spec) int {
+		return strings.Compare(a.(*ast.ImportSpec).Path.Value, b.(*ast.ImportSpec).Path.Value)
+	})
 
 	gen.Decls = append([]ast.Decl{&ast.GenDecl{
 		Tok:   token.IMPORT,
@@ -477,6 +670,9 @@ func (scope *scope) compileFuncBody(p *packages.Package, typ *ast.FuncType, body
 						types.NewSlice(types.NewSignatureType(nil, nil, nil, nil, nil, false)),
 					)
 				}
+				if f, ok := n.Call.Fun.(*ast.FuncLit); ok {
+					scope.rewriteRecoverCalls(p, f.Body, color)
+				}
 				cursor.Replace(&ast.AssignStmt{
 					Lhs: []ast.Expr{defers},
 					Tok: token.ASSIGN,
@@ -500,29 +696,13 @@ func (scope *scope) compileFuncBody(p *packages.Package, typ *ast.FuncType, body
 	gen := new(ast.BlockStmt)
 	ctx := ast.NewIdent("_c")
 
-	yieldTypeExpr := make([]ast.Expr, 2)
-	yieldTypeExpr[0] = typeExpr(p, color.Params().At(0).Type())
-	yieldTypeExpr[1] = typeExpr(p, color.Results().At(0).Type())
-
-	coroutineIdent := ast.NewIdent("coroutine")
-	p.TypesInfo.Uses[coroutineIdent] = types.NewPkgName(token.NoPos, p.Types, "coroutine", scope.compiler.coroutinePkg.Types)
-
 	// _c := coroutine.LoadContext[R, S]()
 	gen.List = append(gen.List, &ast.AssignStmt{
 		Lhs: []ast.Expr{ctx},
 		Tok: token.DEFINE,
-		Rhs: []ast.Expr{
-			&ast.CallExpr{
-				Fun: &ast.IndexListExpr{
-					X: &ast.SelectorExpr{
-						X:   coroutineIdent,
-						Sel: ast.NewIdent("LoadContext"),
-					},
-					Indices: yieldTypeExpr,
-				},
-			},
-		},
+		Rhs: []ast.Expr{scope.loadContext(p, color)},
 	})
+	coroutineIdent := scope.coroutineIdent(p)
 
 	frameName := ast.NewIdent(fmt.Sprintf("_f%d", scope.frameIndex))
 	scope.frameIndex++
@@ -543,8 +723,32 @@ func (scope *scope) compileFuncBody(p *packages.Package, typ *ast.FuncType, body
 	// assignments that use := to assignments that use =. Constant decls are
 	// hoisted and also have their value assigned in the function prologue.
 	decls, frameType, frameInit := extractDecls(p, typ, body, recv, defers, p.TypesInfo)
+
+	// Named results are held in the frame, keep track of the fields so
+	// their values can be returned after recovering from a panic.
+	var namedResults []ast.Expr
+	var namedResultFields []int
+	if typ.Results != nil {
+		for _, field := range typ.Results.List {
+			for _, name := range field.Names {
+				for i, f := range frameType.Fields.List {
+					if f.Names[0] == name {
+						namedResults = append(namedResults, ast.NewIdent(name.Name))
+						namedResultFields = append(namedResultFields, i)
+					}
+				}
+			}
+		}
+	}
+
 	renameObjects(typ, body, p.TypesInfo, decls, frameName, frameType, frameInit, scope)
 
+	// Types and constants are declared first, since the frame may hold
+	// values of types declared in the function body.
+	for _, decl := range decls {
+		gen.List = append(gen.List, &ast.DeclStmt{Decl: decl})
+	}
+
 	// var _f{n} F = coroutine.Push[F](&_c.Stack)
 	gen.List = append(gen.List, &ast.DeclStmt{Decl: &ast.GenDecl{
 		Tok: token.VAR,
@@ -564,10 +768,6 @@ func (scope *scope) compileFuncBody(p *packages.Package, typ *ast.FuncType, body
 		}},
 	}})
 
-	for _, decl := range decls {
-		gen.List = append(gen.List, &ast.DeclStmt{Decl: decl})
-	}
-
 	gen.List = append(gen.List, &ast.IfStmt{
 		Cond: &ast.BinaryExpr{
 			X:  &ast.SelectorExpr{X: frameName, Sel: ast.NewIdent("IP")},
@@ -592,21 +792,39 @@ func (scope *scope) compileFuncBody(p *packages.Package, typ *ast.FuncType, body
 	if defers == nil {
 		popFrame = []ast.Stmt{&ast.ExprStmt{X: popExpr}}
 	} else {
-		popFrame = []ast.Stmt{
-			&ast.DeferStmt{Call: popExpr},
-			&ast.RangeStmt{
-				Key:   ast.NewIdent("_"),
-				Value: ast.NewIdent("f"),
-				Tok:   token.DEFINE,
-				X: &ast.SelectorExpr{
+		// The panic in flight, if any, is recovered here because it must be
+		// done directly by the deferred function. It is then made available
+		// to the functions deferred by the coroutine, and raised again
+		// if none of them recovers it (see coroutine.Context.RunDefers).
+		runDefers := &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: ctx, Sel: ast.NewIdent("RunDefers")},
+			Args: []ast.Expr{
+				&ast.SelectorExpr{
 					X:   frameName,
 					Sel: frameType.Fields.List[len(frameType.Fields.List)-1].Names[0],
 				},
-				Body: &ast.BlockStmt{List: []ast.Stmt{
-					&ast.DeferStmt{Call: &ast.CallExpr{Fun: ast.NewIdent("f")}},
-				}},
+				&ast.CallExpr{Fun: ast.NewIdent("recover")},
 			},
 		}
+		popFrame = []ast.Stmt{&ast.DeferStmt{Call: popExpr}}
+		if len(namedResults) == 0 {
+			popFrame = append(popFrame, &ast.ExprStmt{X: runDefers})
+		} else {
+			// After recovering from a panic, the function returns the
+			// values of its named results.
+			results := make([]ast.Expr, len(namedResultFields))
+			for i, f := range namedResultFields {
+				results[i] = &ast.SelectorExpr{X: frameName, Sel: frameType.Fields.List[f].Names[0]}
+			}
+			popFrame = append(popFrame, &ast.IfStmt{
+				Cond: runDefers,
+				Body: &ast.BlockStmt{List: []ast.Stmt{&ast.AssignStmt{
+					Lhs: namedResults,
+					Tok: token.ASSIGN,
+					Rhs: results,
+				}}},
+			})
+		}
 	}
 
 	gen.List = append(gen.List, &ast.DeferStmt{
@@ -630,6 +848,7 @@ func (scope *scope) compileFuncBody(p *packages.Package, typ *ast.FuncType, body
 	spans := trackDispatchSpans(body)
 	mayYield = findCalls(body, p.TypesInfo)
 	compiledBody := compileDispatch(body, frameName, spans, mayYield).(*ast.BlockStmt)
+	compileGotos(compiledBody, frameName, spans)
 	gen.List = append(gen.List, compiledBody.List...)
 
 	// If the function returns one or more values, it must end with a return
@@ -651,6 +870,52 @@ func (scope *scope) compileFuncBody(p *packages.Package, typ *ast.FuncType, body
 	return gen
 }
 
+func (scope *scope) coroutineIdent(p *packages.Package) *ast.Ident {
+	ident := ast.NewIdent("coroutine")
+	p.TypesInfo.Uses[ident] = types.NewPkgName(token.NoPos, p.Types, "coroutine", scope.compiler.coroutinePkg.Types)
+	return ident
+}
+
+// loadContext returns the expression coroutine.LoadContext[R, S]() for
+// the yield types of the color.
+func (scope *scope) loadContext(p *packages.Package, color *types.Signature) ast.Expr {
+	return &ast.CallExpr{
+		Fun: &ast.IndexListExpr{
+			X: &ast.SelectorExpr{
+				X:   scope.coroutineIdent(p),
+				Sel: ast.NewIdent("LoadContext"),
+			},
+			Indices: []ast.Expr{
+				typeExpr(p, color.Params().At(0).Type()),
+				typeExpr(p, color.Results().At(0).Type()),
+			},
+		},
+	}
+}
+
+// rewriteRecoverCalls replaces calls to recover in the body of a function
+// deferred by a coroutine function with calls to the Recover method of the
+// coroutine context, since the function is not called directly by the Go
+// runtime (see coroutine.Context.RunDefers).
+//
+// The context is loaded when recovering rather than captured by the
+// deferred function, since the latter may be serialized with the frame.
+func (scope *scope) rewriteRecoverCalls(p *packages.Package, body *ast.BlockStmt, color *types.Signature) {
+	astutil.Apply(body, func(cursor *astutil.Cursor) bool {
+		switch n := cursor.Node().(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if f, ok := n.Fun.(*ast.Ident); ok && p.TypesInfo.ObjectOf(f) == types.Universe.Lookup("recover") {
+				cursor.Replace(&ast.CallExpr{
+					Fun: &ast.SelectorExpr{X: scope.loadContext(p, color), Sel: ast.NewIdent("Recover")},
+				})
+			}
+		}
+		return true
+	}, nil)
+}
+
 func panicCall(s string) ast.Expr {
 	return &ast.CallExpr{
 		Fun: &ast.Ident{Name: "panic"},
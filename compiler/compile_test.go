@@ -0,0 +1,302 @@
+package compiler
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestFormatImports(t *testing.T) {
+	src := `package foo
+
+import (
+	_types "github.com/stealthrocket/coroutine/types"
+	unsafe "unsafe"
+	coroutine "github.com/stealthrocket/coroutine"
+	time "time"
+)
+
+var _ = time.Now
+`
+	expect := `package foo
+
+import (
+	time "time"
+	unsafe "unsafe"
+
+	coroutine "github.com/stealthrocket/coroutine"
+	_types "github.com/stealthrocket/coroutine/types"
+)
+
+var _ = time.Now
+`
+	b, err := formatImports("foo.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != expect {
+		t.Errorf("unexpected output:\n%s\nexpect:\n%s", got, expect)
+	}
+}
+
+func TestGeneratedImportsSorted(t *testing.T) {
+	for _, path := range []string{
+		"testdata/coroutine_durable.go",
+		"testdata/testdata_durable.go",
+	} {
+		t.Run(path, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(f.Decls) > 1 {
+				t.Fatalf("expected a single import declaration, got %d", len(f.Decls))
+			}
+
+			// Collect import paths in blocks separated by blank lines.
+			var blocks [][]string
+			line := 0
+			for _, spec := range f.Imports {
+				path, err := strconv.Unquote(spec.Path.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if l := fset.Position(spec.Pos()).Line; l != line+1 || len(blocks) == 0 {
+					blocks = append(blocks, nil)
+				}
+				line = fset.Position(spec.Pos()).Line
+				blocks[len(blocks)-1] = append(blocks[len(blocks)-1], path)
+			}
+
+			for i, block := range blocks {
+				if !slices.IsSorted(block) {
+					t.Errorf("imports are not sorted: %q", block)
+				}
+				std := !strings.Contains(block[0], ".")
+				for _, path := range block {
+					if s := !strings.Contains(path, "."); s != std {
+						t.Errorf("standard library and third-party imports are mixed: %q", block)
+					}
+				}
+				if std && i > 0 {
+					t.Errorf("standard library imports must come first: %q", blocks)
+				}
+			}
+		})
+	}
+}
+
+func TestCompileToMap(t *testing.T) {
+	files, err := CompileToMap("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The generated files are checked in and built with -tags durable,
+	// so matching their content ensures the in-memory output compiles.
+	for _, name := range []string{
+		"coroutine.go",
+		"coroutine_durable.go",
+		"testdata.go",
+		"testdata_durable.go",
+	} {
+		path, err := filepath.Abs(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, ok := files[path]
+		if !ok {
+			t.Errorf("missing output file %s", path)
+			continue
+		}
+		expect, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, expect) {
+			t.Errorf("unexpected content for %s", path)
+		}
+	}
+}
+
+func TestCompileDryRun(t *testing.T) {
+	var report bytes.Buffer
+	if err := Compile("testdata", WithDryRun(&report)); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := filepath.Abs(filepath.Join("testdata", "coroutine_durable.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := report.String()
+	for _, expect := range []string{
+		"package github.com/stealthrocket/coroutine/compiler/testdata\n",
+		"file       " + path + "\n",
+		"coroutine  SquareGenerator ",
+		"R=int",
+		"S=any",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("missing %q in dry run report:\n%s", expect, out)
+		}
+	}
+}
+
+func TestCompileVerify(t *testing.T) {
+	files, err := CompileToMap("testdata", WithVerify())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := filepath.Abs(filepath.Join("testdata", "coroutine_durable.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := files[path]; !ok {
+		t.Fatalf("missing output file %s", path)
+	}
+
+	dir, err := filepath.Abs("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &packages.Package{PkgPath: "github.com/stealthrocket/coroutine/compiler/testdata"}
+	files[path] = []byte("//go:build durable\n\npackage testdata\n\nfunc Broken() { undefined() }\n")
+
+	err = verify(dir, ".", []*packages.Package{pkg}, files)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "coroutine_durable.go:5:17: undefined: undefined") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// compileTestModule writes the files to a module that requires this one,
+// and compiles it to memory.
+func compileTestModule(t *testing.T, files map[string]string) (map[string][]byte, error) {
+	t.Helper()
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gosum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files["go.mod"] = "module example.com/test\n\ngo 1.21.0\n\nrequire " + coroutinePackage + " v0.0.0\n\nreplace " + coroutinePackage + " => " + root + "\n"
+	files["go.sum"] = string(gosum)
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Resolve the requirements of the replaced module from its go.mod.
+	t.Setenv("GOFLAGS", "-mod=mod")
+	return CompileToMap(dir)
+}
+
+func TestCompileMultipleYieldTypes(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		source string
+		expect string
+	}{
+		{
+			name: "helper called from coroutines with different yield types",
+			source: `package main
+
+import "github.com/stealthrocket/coroutine"
+
+func yieldInt() { coroutine.Yield[int, any](1) }
+
+func yieldString() { coroutine.Yield[string, any]("a") }
+
+func helper() {
+	yieldInt()
+	yieldString()
+}
+
+func main() {}
+`,
+			expect: "main.go:9:6: function example.com/test.helper has more than one color: it is part of coroutines with different yield types",
+		},
+		{
+			name: "generic helper instantiated with different yield types",
+			source: `package main
+
+import "github.com/stealthrocket/coroutine"
+
+func helper[R any](v R) { coroutine.Yield[R, any](v) }
+
+func Ints() { helper(1) }
+
+func Strings() { helper("a") }
+
+func main() {}
+`,
+			expect: "main.go:5:6: not implemented: generic function example.com/test.helper in coroutines",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := compileTestModule(t, map[string]string{"main.go": test.source})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.expect) {
+				t.Errorf("unexpected error:\ngot:  %v\nwant: %s", err, test.expect)
+			}
+		})
+	}
+}
+
+func TestCompileDistinctYieldTypes(t *testing.T) {
+	files, err := compileTestModule(t, map[string]string{"main.go": `package main
+
+import "github.com/stealthrocket/coroutine"
+
+func Ints() {
+	coroutine.Yield[int, any](1)
+	coroutine.Yield[int, any](2)
+}
+
+func Strings() {
+	coroutine.Yield[string, bool]("a")
+	coroutine.Yield[string, bool]("b")
+}
+
+func main() {}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, b := range files {
+		if filepath.Base(path) != "main_durable.go" {
+			continue
+		}
+		for _, expect := range []string{
+			"coroutine.LoadContext[int, any]()",
+			"coroutine.LoadContext[string, bool]()",
+		} {
+			if !bytes.Contains(b, []byte(expect)) {
+				t.Errorf("missing %q in generated code:\n%s", expect, b)
+			}
+		}
+		return
+	}
+	t.Fatal("missing output file main_durable.go")
+}
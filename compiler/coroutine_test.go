@@ -2,7 +2,9 @@ package compiler
 
 import (
 	"slices"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stealthrocket/coroutine"
 	. "github.com/stealthrocket/coroutine/compiler/testdata"
@@ -15,6 +17,26 @@ func init() {
 	SomeFunctionThatShouldExistInTheCompiledFile()
 }
 
+// roundTrip serializes the state of g and returns the coroutine reconstructed
+// from it, which is how a durable coroutine resumes from a checkpoint. In
+// volatile mode the state cannot be serialized and g is returned unchanged.
+func roundTrip[R, S any](t *testing.T, g coroutine.Coroutine[R, S]) coroutine.Coroutine[R, S] {
+	t.Helper()
+	b, err := g.Context().Marshal()
+	if err != nil {
+		if err == coroutine.ErrNotDurable {
+			return g
+		}
+		t.Fatal(err)
+	}
+	// The entry point of the coroutine is part of the serialized state.
+	reconstructed := coroutine.New[R, S](nil)
+	if err := reconstructed.Context().Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	return reconstructed
+}
+
 func TestCoroutineYield(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -60,6 +82,12 @@ func TestCoroutineYield(t *testing.T) {
 			yields: []int{1, 2, 3, 2, 4, 6, 3, 6, 9, 2, 4, 6, 4, 8, 12, 6, 12, 18, 3, 6, 9, 6, 12, 18, 9, 18, 27},
 		},
 
+		{
+			name:   "nested loops yielding at each level",
+			coro:   func() { NestedLoopsYieldingAtEachLevel(3) },
+			yields: []int{-1, 11, 12, 13, 1, -2, 21, 22, 23, 2, -3, 31, 32, 33, 3},
+		},
+
 		{
 			name:   "fizz buzz (1)",
 			coro:   func() { FizzBuzzIfGenerator(20) },
@@ -96,18 +124,122 @@ func TestCoroutineYield(t *testing.T) {
 			yields: []int{0, 1, 2, 3, 4},
 		},
 
+		{
+			name:   "yield across defers",
+			coro:   func() { YieldAcrossDefers(2) },
+			yields: []int{0, 0, 21, 21, 21, 2121},
+		},
+
 		{
 			name:   "type switching",
 			coro:   func() { TypeSwitchingGenerator(0) },
 			yields: []int{1, 10, 2, 20, 4, 30, 8, 40},
 		},
 
+		{
+			name:   "type switch yield in case",
+			coro:   func() { TypeSwitchYieldInCase(1, "ab", nil, []int{}, [2]int{}, 1.5, 3) },
+			yields: []int{1, 10, 2, 4, -1, -2, -2, -3, 3, 30},
+		},
+
 		{
 			name:   "loop break and continue",
 			coro:   func() { LoopBreakAndContinue(0) },
 			yields: []int{1, 3, 5, 0, 1, 0, 1},
 		},
 
+		{
+			name:   "goto",
+			coro:   func() { Goto(3) },
+			yields: []int{0, 1, 2, 0, 10, -1, -2, 100, 101, 110, 111},
+		},
+
+		{
+			name:   "switch fallthrough",
+			coro:   func() { SwitchFallthrough(6) },
+			yields: []int{0, 1, 1, 2, 3, 4, 3, 4, 4, 3},
+		},
+
+		{
+			name:   "local type declarations",
+			coro:   func() { LocalTypeDeclarations(3) },
+			yields: []int{100, 101, 102, 0, 11, 22},
+		},
+
+		{
+			name: "nested range continue outer",
+			coro: func() {
+				NestedRangeContinueOuter([][]int{{1, 2}, {3, -1, 4}, {5}, {-2}, {6, 0, 7}, {8}})
+			},
+			yields: []int{1, 2, 100, 3, -1, 5, 102, -2, 6, 0},
+		},
+
+		{
+			name:   "inline closure generators",
+			coro:   func() { InlineClosureGenerators(3) },
+			yields: []int{0, -1, 0, 1, 2, 3, 4, 5, 6},
+		},
+
+		{
+			name:   "comma ok assignments",
+			coro:   func() { CommaOkAssignments(3) },
+			yields: []int{0, 10, 20, 0, 100, 0, 42, 0, 3, 2, 2, 3},
+		},
+
+		{
+			name:   "assign to fields indexes and pointers",
+			coro:   func() { AssignToFieldsIndexesAndPointers(4) },
+			yields: []int{0, 1, 4, 9, 14, 14, 30, 4, 10, 0, 1, 4, 9},
+		},
+
+		{
+			name:   "range over map deleting keys",
+			coro:   func() { RangeOverMapDeletingKeys(8) },
+			yields: []int{6, 4, 2, 0, 4},
+		},
+
+		{
+			name:   "range over strings",
+			coro:   func() { RangeOverStrings("aé€\xff") },
+			yields: []int{0, 'a', 1, 'é', 3, '€', 6, utf8.RuneError, 1, 2, 3, 4, 0, 1, 3, 6, 'ü', '!'},
+		},
+
+		{
+			name:   "closure capturing loop index",
+			coro:   func() { ClosureCapturingLoopIndex(3) },
+			yields: []int{0, 1, 2, 3, 3, 3},
+		},
+
+		{
+			name:   "range over channel",
+			coro:   func() { RangeOverChannel(3) },
+			yields: []int{0, 1, 2, 3, -1},
+		},
+
+		{
+			name:   "inline var and const declarations",
+			coro:   func() { InlineVarAndConstDeclarations(3) },
+			yields: []int{1, 11, 21, 3, 0, 10, 20},
+		},
+
+		{
+			name:   "multiple calls in expression",
+			coro:   func() { MultipleCallsInExpression(10) },
+			yields: []int{1, 2, 3, 11, 31, 11, 22, 484},
+		},
+
+		{
+			name:   "switch with init statement",
+			coro:   func() { SwitchWithInitStatement(5) },
+			yields: []int{5, 6, 6, 60, -5, -50, -5},
+		},
+
+		{
+			name:   "recover after yield",
+			coro:   func() { RecoverAfterYield(3) },
+			yields: []int{0, 0, 1, 10, 2, 20},
+		},
+
 		{
 			name:   "range over maps",
 			coro:   func() { RangeOverMaps(5) },
@@ -166,10 +298,12 @@ func TestCoroutineYield(t *testing.T) {
 			name:   "select",
 			coro:   func() { Select(8) },
 			yields: []int{-1, 0, 0, 1, 10, 2, 20, 3, 30, 4, 40, 50, 0, 1, 2},
-			// TODO: re-enable test once either chan serialization is supported,
-			//  or the desugaring pass skips statements that cannot yield (which
-			//  will reduce temporary vars and avoid the need to deser type chan)
-			skip: true,
+		},
+
+		{
+			name:   "select send receive default",
+			coro:   func() { SelectSendRecvDefault(4) },
+			yields: []int{1, -1, 3, -3, 100, 200},
 		},
 
 		{
@@ -189,18 +323,90 @@ func TestCoroutineYield(t *testing.T) {
 			yields: []int{100, 101, 102, 103, 104, 105, 106, 107, 108, 109},
 		},
 
+		{
+			name:   "sort with closure",
+			coro:   func() { SortWithClosure(3) },
+			yields: []int{3, 2, 1, 10, 20, 30},
+		},
+
+		{
+			name:   "recursion",
+			coro:   func() { RecursiveYield(3) },
+			yields: []int{3, 2, 1, -1, -2, -3},
+		},
+
+		{
+			name:   "mutual recursion",
+			coro:   func() { MutuallyRecursiveYield(4) },
+			yields: []int{4, 30, 2, 10, 100, 300},
+		},
+
+		{
+			name:   "short-circuit evaluation",
+			coro:   func() { ShortCircuitEvaluation(3) },
+			yields: []int{3, 1, 30, 2, 3, 3, 300, 4, 5},
+		},
+
+		{
+			name:   "for loop with multiple post assignments",
+			coro:   func() { ForLoopWithMultiplePostAssignments(5) },
+			yields: []int{5, 14, 23},
+		},
+
+		{
+			name:   "for loop with multiple init variables",
+			coro:   func() { ForLoopWithMultipleInitVariables([]int{1, 2, 3, 4, 5}) },
+			yields: []int{0, 1, 5, 2, 4},
+		},
+
+		{
+			name:   "for loop with yielding post statement",
+			coro:   func() { ForLoopWithYieldingPostStatement(4) },
+			yields: []int{0, 0, 10, 2, 20, 30},
+		},
+
+		{
+			name:   "for loop with post statement and labeled continue",
+			coro:   func() { ForLoopWithPostStatementAndLabeledContinue(4) },
+			yields: []int{0, 10, 11, 30, 31, 32, 33},
+		},
+
 		{
 			name:   "methods",
 			coro:   func() { var s MethodGeneratorState; s.MethodGenerator(5) },
 			yields: []int{0, 1, 2, 3, 4, 5},
 		},
 
+		{
+			name:   "methods with value receiver",
+			coro:   func() { ValueMethodGeneratorState{Base: 1}.ValueMethodGenerator(4) },
+			yields: []int{1, 3, 6, 11},
+		},
+
 		{
 			name:   "var args",
 			coro:   func() { VarArgs(3) },
 			yields: []int{0, 1, 2},
 		},
 
+		{
+			name:   "range over slice returned by function",
+			coro:   func() { RangeOverSliceFromCall(3) },
+			yields: []int{0, 1, 10, 1, 20, 1},
+		},
+
+		{
+			name:   "yield index and selector expressions",
+			coro:   func() { YieldIndexAndSelectorExpressions(1) },
+			yields: []int{1, 3, 5, 6, 8, 8, 9, -1},
+		},
+
+		{
+			name:   "switch in loop with continue",
+			coro:   func() { SwitchInLoopContinue(6) },
+			yields: []int{0, 10, 2, -3, 4, 50, 1, 2},
+		},
+
 		{
 			name:   "return values",
 			coroR:  func() int { return NestedLoops(3) },
@@ -214,6 +420,20 @@ func TestCoroutineYield(t *testing.T) {
 			yields: []int{11},
 			result: 42,
 		},
+
+		{
+			name:   "return after cleanup",
+			coroR:  func() int { return ReturnAfterCleanup(5) },
+			yields: []int{0, 1, 2, -1},
+			result: 102,
+		},
+
+		{
+			name:   "return after cleanup at end of function",
+			coroR:  func() int { return ReturnAfterCleanup(2) },
+			yields: []int{0, 1, -1},
+			result: 100,
+		},
 	}
 
 	// This emulates the installation of function type information by the
@@ -259,19 +479,7 @@ func TestCoroutineYield(t *testing.T) {
 
 				// If supported, serialize => deserialize the context
 				// before resuming.
-				b, err := g.Context().Marshal()
-				if err != nil {
-					if err == coroutine.ErrNotDurable {
-						continue
-					}
-					t.Fatal(err)
-				}
-
-				reconstructed := coroutine.New[int, any](test.coro)
-				if err := reconstructed.Context().Unmarshal(b); err != nil {
-					t.Fatal(err)
-				}
-				g = reconstructed
+				g = roundTrip(t, g)
 			}
 			if yield < len(test.yields) {
 				t.Errorf("coroutine did not yield the correct number of times: got %d, expect %d", yield, len(test.yields))
@@ -302,3 +510,248 @@ func TestCoroutineStop(t *testing.T) {
 		t.Errorf("wrong values yield by coroutine: %#v", values)
 	}
 }
+
+func TestCoroutineStopRecover(t *testing.T) {
+	// Deferred functions recovering from panics must not intercept the
+	// coroutine being stopped.
+	coro := coroutine.New[int, any](func() { RecoverAfterYield(3) })
+
+	values := []int{}
+	coroutine.Run(coro, func(v int) any {
+		values = append(values, v)
+		if len(values) == 3 {
+			coro.Stop()
+		}
+		return nil
+	})
+
+	if !slices.Equal(values, []int{0, 0, 1}) {
+		t.Errorf("wrong values yield by coroutine: %#v", values)
+	}
+	if !coro.Done() {
+		t.Error("coroutine is not done after being stopped")
+	}
+}
+
+func TestCoroutineYieldWhilePanicking(t *testing.T) {
+	if !coroutine.Durable {
+		t.Skip("the panic in flight is preserved across yields in volatile mode")
+	}
+	g := coroutine.New[int, any](func() { YieldWhilePanicking() })
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.Contains(msg, "while a panic is in flight") {
+			t.Errorf("unexpected panic: %q", msg)
+		}
+	}()
+	g.Next()
+	t.Error("coroutine yielded while a panic was in flight")
+}
+
+func TestCoroutineCancel(t *testing.T) {
+	coro := func() { CountUntilCancelled() }
+	types.RegisterFunc[func()](types.FuncByAddr(types.FuncAddr(coro)).Name)
+
+	CancelCleanups = 0
+	g := coroutine.New[int, any](coro)
+
+	var values []int
+	for g.Next() {
+		values = append(values, g.Recv())
+		if len(values) == 3 {
+			g.Context().Cancel()
+			if g.Context().Cancelled() {
+				t.Error("coroutine is cancelled before unwinding")
+			}
+		}
+
+		// The cancellation is part of the serialized state.
+		g = roundTrip(t, g)
+	}
+
+	if !slices.Equal(values, []int{0, 1, 2}) {
+		t.Errorf("wrong values yield by coroutine: %#v", values)
+	}
+	if !g.Done() || !g.Context().Cancelled() {
+		t.Error("coroutine is not cancelled after unwinding")
+	}
+	if CancelCleanups != 1 {
+		t.Errorf("wrong number of deferred cleanups: got %d, expect 1", CancelCleanups)
+	}
+
+	// The completion state is part of the serialized state, the coroutine
+	// is not restarted.
+	g = roundTrip(t, g)
+	if g.Next() || !g.Context().Cancelled() {
+		t.Error("cancelled coroutine was restarted after deserialization")
+	}
+}
+
+func TestCoroutineCancelBeforeStart(t *testing.T) {
+	CancelCleanups = 0
+	g := coroutine.New[int, any](func() { CountUntilCancelled() })
+	g.Context().Cancel()
+
+	if g.Next() {
+		t.Error("coroutine yielded after being cancelled")
+	}
+	if !g.Done() || !g.Context().Cancelled() {
+		t.Error("coroutine is not cancelled")
+	}
+	if CancelCleanups != 0 {
+		t.Errorf("function of the coroutine ran: got %d deferred cleanups, expect 0", CancelCleanups)
+	}
+}
+
+func TestCoroutineCancelAndReturn(t *testing.T) {
+	g := coroutine.New[int, any](func() { CancelSelfAndReturn(1) })
+
+	var values []int
+	for g.Next() {
+		values = append(values, g.Recv())
+	}
+
+	if !slices.Equal(values, []int{1}) {
+		t.Errorf("wrong values yield by coroutine: %#v", values)
+	}
+	if !g.Done() {
+		t.Error("coroutine is not done after returning")
+	}
+	if g.Context().Cancelled() {
+		t.Error("coroutine that returned is reported as cancelled")
+	}
+}
+
+func TestCoroutineCompletedNotCancelled(t *testing.T) {
+	g := coroutine.New[int, any](func() { SquareGenerator(2) })
+	for g.Next() {
+	}
+	g.Context().Cancel()
+	if g.Context().Cancelled() {
+		t.Error("completed coroutine is reported as cancelled")
+	}
+}
+
+func TestCoroutineStructSend(t *testing.T) {
+	coro := func() { StructSendGenerator(4) }
+	types.RegisterFunc[func()](types.FuncByAddr(types.FuncAddr(coro)).Name)
+
+	g := coroutine.New[int, struct{}](coro)
+
+	var values []int
+	for g.Next() {
+		values = append(values, g.Recv())
+		g.Send(struct{}{})
+		g = roundTrip(t, g)
+	}
+
+	if !slices.Equal(values, []int{0, 1, 4, 9}) {
+		t.Errorf("wrong values yield by coroutine: %#v", values)
+	}
+}
+
+func TestCoroutineYieldInExpression(t *testing.T) {
+	coro := func() { YieldInExpression(1) }
+	types.RegisterFunc[func()](types.FuncByAddr(types.FuncAddr(coro)).Name)
+
+	g := coroutine.New[int, int](coro)
+
+	var values []int
+	for g.Next() {
+		v := g.Recv()
+		values = append(values, v)
+
+		// The value to send is not part of the serialized state, so it
+		// is set after the coroutine has been reconstructed.
+		g = roundTrip(t, g)
+		g.Send(v * 10)
+	}
+
+	if !slices.Equal(values, []int{1, 11, 12, 26400}) {
+		t.Errorf("wrong values yield by coroutine: %#v", values)
+	}
+}
+
+func TestCoroutineResume(t *testing.T) {
+	coro := func() { RunningTotal(4) }
+	types.RegisterFunc[func()](types.FuncByAddr(types.FuncAddr(coro)).Name)
+
+	g := coroutine.New[int, int](coro)
+	if !g.Next() {
+		t.Fatal("coroutine did not yield")
+	}
+
+	values := []int{g.Recv()}
+	for i := 1; ; i++ {
+		// Values are sent to the coroutine reconstructed from the
+		// serialized state, and must reach the yield point it was
+		// paused at.
+		g = roundTrip(t, g)
+		if !g.Resume(i) {
+			break
+		}
+		values = append(values, g.Recv())
+	}
+
+	if !slices.Equal(values, []int{0, 1, 3, 6, 10}) {
+		t.Errorf("wrong values yield by coroutine: %#v", values)
+	}
+}
+
+func TestCoroutineContextResult(t *testing.T) {
+	coro := func() int { return SumSentValues(3) }
+	types.RegisterFunc[func() int](types.FuncByAddr(types.FuncAddr(coro)).Name)
+
+	g := coroutine.NewWithReturn[int, int](coro)
+
+	var yields int
+	for g.Next() {
+		if g.Context().Done() {
+			t.Fatal("coroutine is done after yielding")
+		}
+		v := g.Recv()
+		yields++
+
+		g = roundTrip(t, g)
+		g.Send(10 * (v + 1))
+	}
+
+	ctx := g.Context()
+	if !ctx.Done() {
+		t.Error("coroutine is not done after returning")
+	}
+	if yields != 3 {
+		t.Errorf("wrong number of yields: got %d, expect 3", yields)
+	}
+	if got := ctx.Result(); got != 60 {
+		t.Errorf("unexpected coroutine return value: got %v, want 60", got)
+	}
+}
+
+func TestCoroutineDepth(t *testing.T) {
+	expect := []int{2, 3, 4, 4, 3, 2}
+	if !coroutine.Durable {
+		// The call stack is not tracked in volatile mode.
+		expect = []int{0, 0, 0, 0, 0, 0}
+	}
+
+	coro := coroutine.New[int, any](YieldDepth)
+
+	var depths []int
+	for coro.Next() {
+		// The depth observed by the coroutine when yielding must match
+		// the depth of the suspended coroutine.
+		if inside, outside := coro.Recv(), coro.Context().Depth(); inside != outside {
+			t.Errorf("depth mismatch: coroutine saw %d, caller saw %d", inside, outside)
+		}
+		depths = append(depths, coro.Recv())
+	}
+
+	if !slices.Equal(depths, expect) {
+		t.Errorf("wrong call stack depths: got %v, expect %v", depths, expect)
+	}
+	if depth := coro.Context().Depth(); depth != 0 {
+		t.Errorf("wrong call stack depth after completion: got %d, expect 0", depth)
+	}
+}
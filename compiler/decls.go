@@ -251,23 +251,37 @@ func renameObjects(fntype *ast.FuncType, tree ast.Node, info *types.Info, decls
 					// The var decl could have one spec, e.g. var foo=0, or
 					// multiple specs, e.g. var ( foo=0; bar=1; baz=2 ). Some
 					// specs may have values and type and some might not, e.g.
-					// var (foo int; bar = 1; baz int = 2). Remove the pure
-					// decls and the decl assignments with types, leaving only
-					// assignments, e.g. { bar = 1; baz = 2 }
+					// var (foo int; bar = 1; baz int = 2). Turn the decls into
+					// assignments, e.g. { foo = *new(int); bar = 1; baz = 2 }
+					//
+					// Pure decls must reset the variable to its zero value,
+					// since the declaration may be executed multiple times
+					// (e.g. in a loop body) while the variable is held in the
+					// frame.
 					var assigns []ast.Stmt
 					for _, spec := range decl.Specs {
 						s, ok := spec.(*ast.ValueSpec)
-						if !ok || len(s.Values) == 0 {
+						if !ok {
 							continue
 						}
 						lhs := make([]ast.Expr, len(s.Names))
 						for i, name := range s.Names {
 							lhs[i] = name
 						}
+						rhs := s.Values
+						if len(rhs) == 0 {
+							rhs = make([]ast.Expr, len(s.Names))
+							for i := range rhs {
+								rhs[i] = &ast.StarExpr{X: &ast.CallExpr{
+									Fun:  ast.NewIdent("new"),
+									Args: []ast.Expr{s.Type},
+								}}
+							}
+						}
 						assigns = append(assigns, &ast.AssignStmt{
 							Tok: token.ASSIGN,
 							Lhs: lhs,
-							Rhs: s.Values,
+							Rhs: rhs,
 						})
 					}
 					switch len(assigns) {
@@ -309,6 +323,20 @@ func renameObjects(fntype *ast.FuncType, tree ast.Node, info *types.Info, decls
 		nil,
 	)
 
+	// The frame may hold values of types declared in the function body,
+	// rename them as well.
+	astutil.Apply(frameType,
+		func(cursor *astutil.Cursor) bool {
+			if n, ok := cursor.Node().(*ast.Ident); ok {
+				if ident, ok := names[info.ObjectOf(n)]; ok {
+					cursor.Replace(ident)
+				}
+			}
+			return true
+		},
+		nil,
+	)
+
 	astutil.Apply(tree,
 		func(cursor *astutil.Cursor) bool {
 			switch n := cursor.Node().(type) {
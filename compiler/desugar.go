@@ -42,6 +42,7 @@ import (
 // done automatically by the type checker.
 func desugar(p *packages.Package, stmt ast.Stmt, mayYield map[ast.Node]struct{}) ast.Stmt {
 	d := desugarer{pkg: p, info: p.TypesInfo, nodesThatMayYield: mayYield}
+	d.findGotoTargets(stmt)
 	stmt = d.desugar(stmt, nil, nil, nil)
 
 	// Unused labels cause a compile error (label X defined and not used)
@@ -64,6 +65,8 @@ type desugarer struct {
 	nodesThatMayYield map[ast.Node]struct{}
 	unusedLabels      map[*ast.Ident]struct{}
 	userLabels        map[types.Object]*ast.Ident
+	gotoTargets       map[types.Object]struct{}
+	continueAsBreak   map[*ast.Ident]*ast.Ident
 }
 
 func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.Ident) ast.Stmt {
@@ -91,25 +94,36 @@ func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.I
 		stmt = &ast.BlockStmt{List: d.desugarList(s.List, breakTo, continueTo)}
 
 	case *ast.BranchStmt:
+		if s.Tok == token.GOTO {
+			// User labels targeted by goto statements are preserved,
+			// see the ast.LabeledStmt case below.
+			break
+		}
+		var branch *ast.BranchStmt
 		if s.Label != nil {
 			label := d.getUserLabel(s.Label)
 			if label == nil {
 				panic(fmt.Sprintf("label not found: %s", s.Label))
 			}
-			d.useLabel(label)
-			stmt = &ast.BranchStmt{Tok: s.Tok, Label: label}
+			branch = &ast.BranchStmt{Tok: s.Tok, Label: label}
 		} else {
 			switch s.Tok {
 			case token.BREAK:
-				d.useLabel(breakTo)
-				stmt = &ast.BranchStmt{Tok: token.BREAK, Label: breakTo}
+				branch = &ast.BranchStmt{Tok: token.BREAK, Label: breakTo}
 			case token.CONTINUE:
-				d.useLabel(continueTo)
-				stmt = &ast.BranchStmt{Tok: token.CONTINUE, Label: continueTo}
-			default: // FALLTHROUGH / GOTO
+				branch = &ast.BranchStmt{Tok: token.CONTINUE, Label: continueTo}
+			default: // FALLTHROUGH
 				panic("not implemented")
 			}
 		}
+		// Loops whose post iteration statement was moved into the body
+		// are continued by breaking out of the rest of the body (see the
+		// ast.ForStmt case below).
+		if label, ok := d.continueAsBreak[branch.Label]; ok && branch.Tok == token.CONTINUE {
+			branch = &ast.BranchStmt{Tok: token.BREAK, Label: label}
+		}
+		d.useLabel(branch.Label)
+		stmt = branch
 
 	case *ast.CaseClause:
 		stmt = &ast.CaseClause{
@@ -185,19 +199,51 @@ func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.I
 			body.List = append([]ast.Stmt{guard}, body.List...)
 			s.Cond = nil
 		}
-		stmt = &ast.LabeledStmt{
-			Label: forLabel,
-			Stmt: &ast.ForStmt{
-				Cond: s.Cond,
-				Body: d.desugar(body, forLabel, forLabel, nil).(*ast.BlockStmt),
-				// The post iteration statement is currently preserved for a
-				// later pass.
-				// TODO: find a way to move the statement into the loop body
-				//  so that it can be desugared further, but do so in a way
-				//  that doesn't break continue and deeply nested cases of
-				//  continue [label]. Using goto doesn't work!
-				Post: d.desugar(s.Post, nil, nil, nil),
-			},
+		if s.Post == nil || d.isSimplePostStmt(s.Post) {
+			// Simple post iteration statements are preserved, and later
+			// hijacked to reset the IP of the loop (see compileDispatch).
+			stmt = &ast.LabeledStmt{
+				Label: forLabel,
+				Stmt: &ast.ForStmt{
+					Cond: s.Cond,
+					Body: d.desugar(body, forLabel, forLabel, nil).(*ast.BlockStmt),
+					Post: d.desugar(s.Post, nil, nil, nil),
+				},
+			}
+		} else {
+			// Other post iteration statements are moved to the end of the
+			// loop body so that they can be desugared further:
+			// - `for ; ; post { ... }` => `for { _l: switch { default: ... }; post }`
+			// Statements that continue the loop break out of the switch
+			// instead, so the post iteration statement is still executed.
+			bodyLabel := d.newLabel()
+			if d.continueAsBreak == nil {
+				d.continueAsBreak = map[*ast.Ident]*ast.Ident{}
+			}
+			d.continueAsBreak[forLabel] = bodyLabel
+
+			caseClause := &ast.CaseClause{
+				Body: d.desugar(body, forLabel, forLabel, nil).(*ast.BlockStmt).List,
+			}
+			switchStmt := &ast.SwitchStmt{Body: &ast.BlockStmt{List: []ast.Stmt{caseClause}}}
+			labeled := &ast.LabeledStmt{Label: bodyLabel, Stmt: switchStmt}
+			loopBody := &ast.BlockStmt{
+				List: append([]ast.Stmt{labeled}, d.desugarList([]ast.Stmt{s.Post}, nil, nil)...),
+			}
+			if d.mayYield(body) {
+				d.nodesThatMayYield[caseClause] = struct{}{}
+				d.nodesThatMayYield[switchStmt.Body] = struct{}{}
+				d.nodesThatMayYield[switchStmt] = struct{}{}
+				d.nodesThatMayYield[labeled] = struct{}{}
+				d.nodesThatMayYield[loopBody] = struct{}{}
+			}
+			if d.mayYield(s.Post) {
+				d.nodesThatMayYield[loopBody] = struct{}{}
+			}
+			stmt = &ast.LabeledStmt{
+				Label: forLabel,
+				Stmt:  &ast.ForStmt{Cond: s.Cond, Body: loopBody},
+			}
 		}
 		if s.Init != nil {
 			prologue := d.desugarList([]ast.Stmt{s.Init}, nil, nil)
@@ -234,19 +280,33 @@ func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.I
 		}
 
 	case *ast.LabeledStmt:
-		// Remove the user's label, but notify the next step so that generated
-		// labels can be mapped.
-		stmt = d.desugar(s.Stmt, breakTo, continueTo, s.Label)
+		if !d.isGotoTarget(s.Label) {
+			// Remove the user's label, but notify the next step so that
+			// generated labels can be mapped.
+			stmt = d.desugar(s.Stmt, breakTo, continueTo, s.Label)
+			break
+		}
+		// Labels targeted by goto statements are kept, and attached to the
+		// desugared statement so that a goto also executes the prologue
+		// that may have been hoisted out of the labeled statement.
+		var body ast.Stmt
+		switch s.Stmt.(type) {
+		case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+			body = d.desugar(s.Stmt, breakTo, continueTo, s.Label)
+		default:
+			body = &ast.BlockStmt{List: d.desugarList([]ast.Stmt{s.Stmt}, breakTo, continueTo)}
+		}
+		stmt = &ast.LabeledStmt{Label: s.Label, Stmt: body}
 
 	case *ast.RangeStmt:
-		x := d.newVar(d.info.TypeOf(s.X))
+		x := d.newVar(types.Default(d.info.TypeOf(s.X)))
 		init := &ast.AssignStmt{Lhs: []ast.Expr{x}, Tok: token.DEFINE, Rhs: []ast.Expr{s.X}}
 		if d.mayYield(s.X) {
 			d.nodesThatMayYield[init] = struct{}{}
 		}
 		prologue := d.desugarList([]ast.Stmt{init}, nil, nil)
 
-		switch rangeElemType := d.info.TypeOf(s.X).(type) {
+		switch rangeElemType := d.info.TypeOf(s.X).Underlying().(type) {
 		case *types.Array, *types.Slice:
 			// Rewrite for range loops over arrays/slices:
 			// - `for range x {}` => `{ _x := x; for _i := 0; _i < len(_x); _i++ {} }`
@@ -281,6 +341,79 @@ func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.I
 				List: append(prologue, d.desugar(forStmt, breakTo, continueTo, userLabel)),
 			}
 
+		case *types.Basic:
+			if rangeElemType.Info()&types.IsInteger != 0 {
+				// Rewrite for range loops over integers (Go 1.22+):
+				// - `for range x {}` => `{ _x := x; for _i := 0; _i < _x; _i++ {} }`
+				// - `for i := range x {}` => `{ _x := x; for i := 0; i < _x; i++ {} }`
+				// Then, desugar loops further (see ast.ForStmt case above).
+				var i *ast.Ident
+				if s.Key == nil || isUnderscore(s.Key) {
+					i = d.newVar(d.info.TypeOf(x))
+				} else {
+					i = s.Key.(*ast.Ident)
+				}
+				forStmt := &ast.ForStmt{
+					Init: &ast.AssignStmt{Lhs: []ast.Expr{i}, Tok: token.DEFINE, Rhs: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "0"}}},
+					Post: &ast.IncDecStmt{X: i, Tok: token.INC},
+					Cond: &ast.BinaryExpr{X: i, Op: token.LSS, Y: x},
+					Body: s.Body,
+				}
+				if d.mayYield(s.Body) {
+					d.nodesThatMayYield[forStmt] = struct{}{}
+				}
+				stmt = &ast.BlockStmt{
+					List: append(prologue, d.desugar(forStmt, breakTo, continueTo, userLabel)),
+				}
+				break
+			}
+			if rangeElemType.Info()&types.IsString == 0 {
+				panic(fmt.Sprintf("not implemented: for range over %s", rangeElemType))
+			}
+			// Rewrite for range loops over strings:
+			// - `for i, r := range x {}` => `{ _x := x; for i := 0; i < len(_x); i += _n { r, _n := utf8.DecodeRuneInString(_x[i:]); ... } }`
+			// - `for i := range x {}` => `{ _x := x; for i := 0; i < len(_x); i += _n { _, _n := utf8.DecodeRuneInString(_x[i:]); ... } }`
+			// - `for range x {}` => `{ _x := x; for _i := 0; _i < len(_x); _i += _n { _, _n := utf8.DecodeRuneInString(_x[_i:]); ... } }`
+			// The byte index is the loop variable, so it is restored exactly
+			// when resuming from a yield in the loop body.
+			var i *ast.Ident
+			if s.Key == nil || isUnderscore(s.Key) {
+				i = d.newVar(types.Typ[types.Int])
+			} else {
+				i = s.Key.(*ast.Ident)
+			}
+			var r ast.Expr = ast.NewIdent("_")
+			if s.Value != nil && !isUnderscore(s.Value) {
+				r = s.Value
+			}
+			n := d.newVar(types.Typ[types.Int])
+			var str ast.Expr = &ast.SliceExpr{X: x, Low: i}
+			if !types.Identical(d.info.TypeOf(x), types.Typ[types.String]) {
+				str = &ast.CallExpr{Fun: d.builtin("string"), Args: []ast.Expr{str}}
+			}
+			utf8 := ast.NewIdent("utf8")
+			d.info.Uses[utf8] = types.NewPkgName(token.NoPos, d.pkg.Types, utf8.Name, types.NewPackage("unicode/utf8", "utf8"))
+			s.Body.List = append([]ast.Stmt{
+				&ast.AssignStmt{Lhs: []ast.Expr{r, n}, Tok: token.DEFINE, Rhs: []ast.Expr{
+					&ast.CallExpr{
+						Fun:  &ast.SelectorExpr{X: utf8, Sel: ast.NewIdent("DecodeRuneInString")},
+						Args: []ast.Expr{str},
+					},
+				}},
+			}, s.Body.List...)
+			forStmt := &ast.ForStmt{
+				Init: &ast.AssignStmt{Lhs: []ast.Expr{i}, Tok: token.DEFINE, Rhs: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: "0"}}},
+				Post: &ast.AssignStmt{Lhs: []ast.Expr{i}, Tok: token.ADD_ASSIGN, Rhs: []ast.Expr{n}},
+				Cond: &ast.BinaryExpr{X: i, Op: token.LSS, Y: &ast.CallExpr{Fun: d.builtin("len"), Args: []ast.Expr{x}}},
+				Body: s.Body,
+			}
+			if d.mayYield(s.Body) {
+				d.nodesThatMayYield[forStmt] = struct{}{}
+			}
+			stmt = &ast.BlockStmt{
+				List: append(prologue, d.desugar(forStmt, breakTo, continueTo, userLabel)),
+			}
+
 		case *types.Map:
 			// Handle the simple case first:
 			if (s.Key == nil || isUnderscore(s.Key)) && (s.Value == nil || isUnderscore(s.Value)) {
@@ -302,6 +435,12 @@ func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.I
 				// Since map iteration order is not deterministic, we split the
 				// loop into two. The first loop collects keys, and the second
 				// loop iterates over those keys.
+				//
+				// Iteration uses the snapshot of keys taken when the range
+				// statement starts. Keys deleted from the map before being
+				// reached are skipped, and keys added to the map during the
+				// iteration are not produced, which the Go specification
+				// permits.
 				keyType := rangeElemType.Key()
 				keySliceType := types.NewSlice(keyType)
 				keys := d.newVar(keySliceType)
@@ -381,6 +520,42 @@ func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.I
 
 				stmt = &ast.BlockStmt{List: append(prologue, collectKeys, iterKeys)}
 			}
+		case *types.Chan:
+			// Rewrite for range loops over channels:
+			// - `for range x {}` => `{ _x := x; for { _, _ok := <-_x; if !_ok { break }; ... } }`
+			// - `for v := range x {}` => `{ _x := x; for { v, _ok := <-_x; if !_ok { break }; ... } }`
+			// Then, desugar loops further (see ast.ForStmt case above).
+			//
+			// Note that receiving from the channel blocks the goroutine
+			// running the coroutine until a value is available.
+			var v ast.Expr = ast.NewIdent("_")
+			if s.Key != nil && !isUnderscore(s.Key) {
+				v = s.Key
+			}
+			ok := d.newVar(types.Typ[types.Bool])
+			brk := &ast.BranchStmt{Tok: token.BREAK}
+			closed := &ast.IfStmt{
+				Cond: &ast.UnaryExpr{Op: token.NOT, X: ok},
+				Body: &ast.BlockStmt{List: []ast.Stmt{brk}},
+			}
+			s.Body.List = append([]ast.Stmt{
+				&ast.AssignStmt{Lhs: []ast.Expr{v, ok}, Tok: token.DEFINE, Rhs: []ast.Expr{
+					&ast.UnaryExpr{Op: token.ARROW, X: x},
+				}},
+				closed,
+			}, s.Body.List...)
+			forStmt := &ast.ForStmt{Body: s.Body}
+			if d.mayYield(s.Body) {
+				// The break statement must target the loop once it has been
+				// desugared, see markBranchStmt.
+				d.nodesThatMayYield[brk] = struct{}{}
+				d.nodesThatMayYield[closed.Body] = struct{}{}
+				d.nodesThatMayYield[closed] = struct{}{}
+				d.nodesThatMayYield[forStmt] = struct{}{}
+			}
+			stmt = &ast.BlockStmt{
+				List: append(prologue, d.desugar(forStmt, breakTo, continueTo, userLabel)),
+			}
 		default:
 			panic(fmt.Sprintf("not implemented: for range over %T", s.X))
 		}
@@ -517,6 +692,10 @@ func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.I
 		}
 
 	case *ast.SwitchStmt:
+		if hasFallthrough(s) {
+			stmt = d.desugarFallthrough(s, breakTo, continueTo, userLabel)
+			break
+		}
 		// Rewrite switch statements:
 		// - `switch init; tag { ... }` => `{ init; _tag := tag; switch _tag { ... }`
 		switchLabel := d.newLabel()
@@ -577,7 +756,7 @@ func (d *desugarer) desugar(stmt ast.Stmt, breakTo, continueTo, userLabel *ast.I
 			for len(list) > 0 {
 				// TODO: balance the tree
 				x, y := orExpr, list[0]
-				orExpr = &ast.BinaryExpr{X: x, Op: token.OR, Y: y}
+				orExpr = &ast.BinaryExpr{X: x, Op: token.LOR, Y: y}
 				if d.mayYield(x) || d.mayYield(y) {
 					d.nodesThatMayYield[orExpr] = struct{}{}
 				}
@@ -783,128 +962,344 @@ func (d *desugarer) mayYield(n ast.Node) bool {
 	return ok
 }
 
+// decomposeExpression hoists the parts of an expression that may yield
+// into temporary variables, returning the rewritten expression and the
+// assignments to the temporary variables that must precede it.
+//
+// Go evaluates function calls in lexical left-to-right order. To preserve
+// it, calls that precede a part of the expression that may yield are hoisted
+// as well, and the assignments are emitted in evaluation order (operands
+// before the expressions that use them, left operands before right ones).
 func (d *desugarer) decomposeExpression(expr ast.Expr, flags exprFlags) (ast.Expr, []ast.Stmt) {
 	if !d.mayYield(expr) {
 		return expr, nil
 	}
 
-	queue := []ast.Expr{expr}
-	var tmps []*ast.Ident
+	var prereqs []ast.Stmt
+	var visit func(e ast.Expr)
+	var shortCircuit func(e *ast.BinaryExpr) ast.Expr
 
-	decompose := func(e ast.Expr) ast.Expr {
-		if !d.mayYield(e) {
-			return e
+	hoist := func(e ast.Expr) ast.Expr {
+		if b, ok := e.(*ast.BinaryExpr); ok && d.isShortCircuit(b) {
+			return shortCircuit(b)
 		}
 		tmp := d.newVar(d.info.TypeOf(e))
-		tmps = append(tmps, tmp)
-		queue = append(queue, e)
+		visit(e)
+		prereqs = append(prereqs, &ast.AssignStmt{
+			Lhs: []ast.Expr{tmp},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{e},
+		})
 		return tmp
 	}
 
-	for i := 0; i < len(queue); i++ {
-		switch e := queue[i].(type) {
+	// shortCircuit hoists a logical expression whose right operand may
+	// yield, preserving the short-circuit evaluation of the operand:
+	// - `x && y` => `_v := x; if _v { _v = y }`
+	// - `x || y` => `_v := x; if !_v { _v = y }`
+	// The assignment of the right operand is decomposed further when the
+	// if statement is desugared.
+	shortCircuit = func(e *ast.BinaryExpr) ast.Expr {
+		x := e.X
+		if d.mayYield(x) {
+			x = hoist(x)
+		}
+		tmp := d.newVar(d.info.TypeOf(e))
+		prereqs = append(prereqs, &ast.AssignStmt{
+			Lhs: []ast.Expr{tmp},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{x},
+		})
+		var cond ast.Expr = tmp
+		if e.Op == token.LOR {
+			cond = &ast.UnaryExpr{Op: token.NOT, X: tmp}
+		}
+		assign := &ast.AssignStmt{
+			Lhs: []ast.Expr{tmp},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{e.Y},
+		}
+		ifStmt := &ast.IfStmt{
+			Cond: cond,
+			Body: &ast.BlockStmt{List: []ast.Stmt{assign}},
+		}
+		d.nodesThatMayYield[assign] = struct{}{}
+		d.nodesThatMayYield[ifStmt.Body] = struct{}{}
+		d.nodesThatMayYield[ifStmt] = struct{}{}
+		prereqs = append(prereqs, ifStmt)
+		return tmp
+	}
+
+	// decompose hoists operands of an expression which may yield, and the
+	// operands with function calls that must be evaluated before them.
+	// The operands must be passed in evaluation order.
+	decompose := func(operands ...*ast.Expr) {
+		last := -1
+		for i, e := range operands {
+			if d.mayYield(*e) {
+				last = i
+			}
+		}
+		for i, e := range operands {
+			switch {
+			case d.mayYield(*e):
+				*e = hoist(*e)
+			case i < last && d.hasFunctionCalls(*e):
+				*e = hoist(*e)
+			}
+		}
+	}
+
+	visit = func(e ast.Expr) {
+		switch e := e.(type) {
 		case *ast.BadExpr:
 			panic("bad expr")
 
 		case *ast.BinaryExpr:
-			e.X = decompose(e.X)
-			e.Y = decompose(e.Y)
+			decompose(&e.X, &e.Y)
 
 		case *ast.CallExpr:
-			if i == 0 && (flags&multiExprStmt) != 0 {
-				// Need to hoist the CallExpr out into a temporary variable in
-				// this case, so that the relative order of calls (and their
-				// prerequisites) is preserved.
-				switch d.info.TypeOf(e).(type) {
-				case *types.Tuple:
-					// TODO: can't hoist like this when it's a function
-					//  that returns multiple values
-				default:
-					queue[i] = decompose(e)
-					continue
-				}
-			}
-			if se, ok := e.Fun.(*ast.SelectorExpr); ok && d.mayYield(se.X) {
-				se.X = decompose(se.X)
+			operands := make([]*ast.Expr, 0, 1+len(e.Args))
+			if se, ok := e.Fun.(*ast.SelectorExpr); ok && (d.mayYield(se.X) || d.hasFunctionCalls(se.X)) {
+				operands = append(operands, &se.X)
 			} else {
-				e.Fun = decompose(e.Fun)
+				operands = append(operands, &e.Fun)
 			}
-			for i, arg := range e.Args {
-				e.Args[i] = decompose(arg)
+			for i := range e.Args {
+				operands = append(operands, &e.Args[i])
 			}
+			decompose(operands...)
 
 		case *ast.CompositeLit:
+			var operands []*ast.Expr
 			for i, elt := range e.Elts {
 				switch kv := elt.(type) {
 				case *ast.KeyValueExpr:
-					kv.Key = decompose(kv.Key)
-					kv.Value = decompose(kv.Value)
+					operands = append(operands, &kv.Key, &kv.Value)
 				default:
-					e.Elts[i] = decompose(elt)
+					operands = append(operands, &e.Elts[i])
 				}
 			}
+			decompose(operands...)
 			// skip e.Type (type expression)
 
 		case *ast.Ellipsis:
-			e.Elt = decompose(e.Elt)
+			decompose(&e.Elt)
 
 		case *ast.IndexExpr:
-			e.X = decompose(e.X)
-			e.Index = decompose(e.Index)
+			decompose(&e.X, &e.Index)
 
 		case *ast.IndexListExpr:
-			e.X = decompose(e.X)
+			decompose(&e.X)
 			// skip e.Indices (type expressions)
 
 		case *ast.KeyValueExpr:
-			e.Key = decompose(e.Key)
-			e.Value = decompose(e.Value)
+			decompose(&e.Key, &e.Value)
 
 		case *ast.ParenExpr:
-			e.X = decompose(e.X)
+			decompose(&e.X)
 
 		case *ast.SelectorExpr:
-			e.X = decompose(e.X)
+			decompose(&e.X)
 
 		case *ast.SliceExpr:
-			e.X = decompose(e.X)
-			e.Low = decompose(e.Low)
-			e.Max = decompose(e.Max)
-			e.High = decompose(e.High)
+			decompose(&e.X, &e.Low, &e.High, &e.Max)
 
 		case *ast.StarExpr:
-			e.X = decompose(e.X)
+			decompose(&e.X)
 
 		case *ast.TypeAssertExpr:
-			e.X = decompose(e.X)
+			decompose(&e.X)
 			// skip e.Type (type expression)
 
 		case *ast.UnaryExpr:
-			e.X = decompose(e.X)
+			decompose(&e.X)
 
 		default:
-			panic(fmt.Sprintf("unsupported ast.Expr: %T", queue[i]))
+			panic(fmt.Sprintf("unsupported ast.Expr: %T", e))
 		}
 	}
-	prereqs := make([]ast.Stmt, len(tmps))
-	for i := range tmps {
-		prereqs[i] = &ast.AssignStmt{
-			Lhs: []ast.Expr{tmps[i]},
-			Tok: token.DEFINE,
-			Rhs: []ast.Expr{queue[i+1]},
+
+	if b, ok := expr.(*ast.BinaryExpr); ok && d.isShortCircuit(b) {
+		return shortCircuit(b), prereqs
+	}
+	if call, ok := expr.(*ast.CallExpr); ok && (flags&multiExprStmt) != 0 {
+		// Need to hoist the CallExpr out into a temporary variable in
+		// this case, so that the relative order of calls (and their
+		// prerequisites) is preserved.
+		switch d.info.TypeOf(call).(type) {
+		case *types.Tuple:
+			// TODO: can't hoist like this when it's a function
+			//  that returns multiple values
+		default:
+			return hoist(call), prereqs
 		}
 	}
-	reverse(prereqs)
-	return queue[0], prereqs
+	visit(expr)
+	return expr, prereqs
 }
 
-func reverse(stmts []ast.Stmt) {
-	i := 0
-	j := len(stmts) - 1
-	for i < j {
-		stmts[i], stmts[j] = stmts[j], stmts[i]
-		i++
-		j--
+// isShortCircuit returns true if the expression is a logical && or ||
+// whose right operand may yield, and thus must only be evaluated when the
+// left operand does not determine the result.
+func (d *desugarer) isShortCircuit(e *ast.BinaryExpr) bool {
+	return (e.Op == token.LAND || e.Op == token.LOR) && d.mayYield(e.Y)
+}
+
+// hasFunctionCalls returns true if evaluating the expression calls
+// functions, which would have to happen in order with other calls of the
+// enclosing expression. Constant expressions, and calls returning multiple
+// values (which cannot be hoisted into a single variable), are ignored.
+func (d *desugarer) hasFunctionCalls(e ast.Expr) bool {
+	if e == nil {
+		return false
+	}
+	if tv, ok := d.info.Types[e]; !ok || tv.Value != nil {
+		return false
+	}
+	if _, ok := d.info.TypeOf(e).(*types.Tuple); ok {
+		return false
+	}
+	return countFunctionCalls(e, d.info) > 0
+}
+
+// isSimplePostStmt returns true if the post iteration statement of a for
+// loop can be preserved as is: an increment or decrement, or an assignment
+// with balanced sides that does not call functions.
+func (d *desugarer) isSimplePostStmt(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.IncDecStmt:
+		return countFunctionCalls(s.X, d.info) == 0
+	case *ast.AssignStmt:
+		if len(s.Lhs) != len(s.Rhs) {
+			return false
+		}
+		for _, e := range s.Lhs {
+			if countFunctionCalls(e, d.info) > 0 {
+				return false
+			}
+		}
+		for _, e := range s.Rhs {
+			if countFunctionCalls(e, d.info) > 0 {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// hasFallthrough returns true if one of the cases of the switch statement
+// ends with a fallthrough statement.
+func hasFallthrough(s *ast.SwitchStmt) bool {
+	for _, caseStmt := range s.Body.List {
+		c := caseStmt.(*ast.CaseClause)
+		if len(c.Body) > 0 && isFallthrough(c.Body[len(c.Body)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isFallthrough(stmt ast.Stmt) bool {
+	b, ok := stmt.(*ast.BranchStmt)
+	return ok && b.Tok == token.FALLTHROUGH
+}
+
+// desugarFallthrough rewrites a switch statement with fallthrough
+// statements into a switch that only records the selected case, followed
+// by the case bodies in source order:
+//
+//	switch tag {
+//	case a:
+//		foo()
+//		fallthrough
+//	case b:
+//		bar()
+//	}
+//
+// =>
+//
+//	_v0 := 0
+//	switch tag {
+//	case a:
+//		_v0 = 1
+//	case b:
+//		_v0 = 2
+//	}
+//	switch {
+//	default:
+//		if _v0 == 1 {
+//			foo()
+//			_v0 = 2
+//		}
+//		if _v0 == 2 {
+//			bar()
+//		}
+//	}
+//
+// Both switch statements are then desugared like any other. Since the case
+// bodies are sequential statements of the same block, a fallthrough that
+// crosses a yield point in the next case resumes like any other statement.
+func (d *desugarer) desugarFallthrough(s *ast.SwitchStmt, breakTo, continueTo, userLabel *ast.Ident) ast.Stmt {
+	selection := d.newVar(types.Typ[types.Int])
+	caseIndex := func(i int) ast.Expr {
+		return &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(i + 1)}
+	}
+
+	rawSwitch := &ast.SwitchStmt{Init: s.Init, Tag: s.Tag, Body: &ast.BlockStmt{}}
+	if d.mayYield(s.Init) || d.mayYield(s.Tag) {
+		d.nodesThatMayYield[rawSwitch] = struct{}{}
+	}
+
+	var bodies []ast.Stmt
+	for i, caseStmt := range s.Body.List {
+		c := caseStmt.(*ast.CaseClause)
+		for _, value := range c.List {
+			if d.mayYield(value) {
+				d.nodesThatMayYield[rawSwitch] = struct{}{}
+			}
+		}
+		rawSwitch.Body.List = append(rawSwitch.Body.List, &ast.CaseClause{
+			List: c.List,
+			Body: []ast.Stmt{
+				&ast.AssignStmt{Lhs: []ast.Expr{selection}, Tok: token.ASSIGN, Rhs: []ast.Expr{caseIndex(i)}},
+			},
+		})
+
+		body := &ast.BlockStmt{List: c.Body}
+		if n := len(body.List); n > 0 && isFallthrough(body.List[n-1]) {
+			body.List = append(body.List[:n-1:n-1],
+				&ast.AssignStmt{Lhs: []ast.Expr{selection}, Tok: token.ASSIGN, Rhs: []ast.Expr{caseIndex(i + 1)}})
+		}
+		ifStmt := &ast.IfStmt{
+			Cond: &ast.BinaryExpr{X: selection, Op: token.EQL, Y: caseIndex(i)},
+			Body: body,
+		}
+		for _, n := range body.List {
+			if d.mayYield(n) {
+				d.nodesThatMayYield[body] = struct{}{}
+				d.nodesThatMayYield[ifStmt] = struct{}{}
+				break
+			}
+		}
+		bodies = append(bodies, ifStmt)
+	}
+
+	defaultCase := &ast.CaseClause{Body: bodies}
+	switchStmt := &ast.SwitchStmt{Body: &ast.BlockStmt{List: []ast.Stmt{defaultCase}}}
+	d.nodesThatMayYield[defaultCase] = struct{}{}
+	d.nodesThatMayYield[switchStmt.Body] = struct{}{}
+	d.nodesThatMayYield[switchStmt] = struct{}{}
+
+	prologue := d.desugarList([]ast.Stmt{
+		&ast.AssignStmt{Lhs: []ast.Expr{selection}, Tok: token.DEFINE, Rhs: []ast.Expr{caseIndex(-1)}},
+		rawSwitch,
+	}, nil, continueTo)
+
+	return &ast.BlockStmt{
+		List: append(prologue, d.desugar(switchStmt, breakTo, continueTo, userLabel)),
 	}
 }
 
@@ -949,6 +1344,28 @@ func (d *desugarer) useLabel(label *ast.Ident) {
 	delete(d.unusedLabels, label)
 }
 
+func (d *desugarer) findGotoTargets(stmt ast.Stmt) {
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false // labels are scoped to the function body
+		case *ast.BranchStmt:
+			if n.Tok == token.GOTO {
+				if d.gotoTargets == nil {
+					d.gotoTargets = map[types.Object]struct{}{}
+				}
+				d.gotoTargets[d.info.ObjectOf(n.Label)] = struct{}{}
+			}
+		}
+		return true
+	})
+}
+
+func (d *desugarer) isGotoTarget(label *ast.Ident) bool {
+	_, ok := d.gotoTargets[d.info.ObjectOf(label)]
+	return ok
+}
+
 func (d *desugarer) isUnusedLabel(label *ast.Ident) bool {
 	_, ok := d.unusedLabels[label]
 	return ok
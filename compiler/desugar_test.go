@@ -321,6 +321,44 @@ _l0:
 		}
 	}
 }
+`,
+		},
+		{
+			name: "for range over int",
+			body: "for range n { foo }",
+			info: func(stmts []ast.Stmt, info *types.Info) {
+				x := stmts[0].(*ast.RangeStmt).X
+				info.Types[x] = types.TypeAndValue{Type: intType}
+			},
+			expect: `
+{
+	_v0 := n
+	{
+		_v1 := 0
+		for ; _v1 < _v0; _v1++ {
+			foo
+		}
+	}
+}
+`,
+		},
+		{
+			name: "for range over int (index)",
+			body: "for i := range n { foo }",
+			info: func(stmts []ast.Stmt, info *types.Info) {
+				x := stmts[0].(*ast.RangeStmt).X
+				info.Types[x] = types.TypeAndValue{Type: intType}
+			},
+			expect: `
+{
+	_v0 := n
+	{
+		i := 0
+		for ; i < _v0; i++ {
+			foo
+		}
+	}
+}
 `,
 		},
 		{
@@ -742,7 +780,7 @@ default:
 			if _v1 {
 				bar
 			} else {
-				_v2 := (_v0 == 2) | (_v0 == 3) | (_v0 == 4)
+				_v2 := _v0 == 2 || _v0 == 3 || _v0 == 4
 				if _v2 {
 					baz
 				} else {
@@ -1068,14 +1106,13 @@ _l0:
 		{
 			name: "key value expr",
 			body: "Foo{Bar: a(b()), Baz: c(d())}",
-			// TODO: fix order of evaluation here
 			expect: `
 {
+	_v1 := b()
+	_v0 := a(_v1)
 	_v3 := d()
-	_v2 := b()
-	_v1 := c(_v3)
-	_v0 := a(_v2)
-	Foo{Bar: _v0, Baz: _v1}
+	_v2 := c(_v3)
+	Foo{Bar: _v0, Baz: _v2}
 }
 `,
 		},
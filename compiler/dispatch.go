@@ -4,6 +4,8 @@ import (
 	"go/ast"
 	"go/token"
 	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
 )
 
 // trackDispatchSpans assigns a non-zero monotonically increasing integer ID to each
@@ -224,3 +226,125 @@ func unnestBlocks(stmt ast.Stmt) ast.Stmt {
 		stmt = s.List[0]
 	}
 }
+
+// compileGotos rewrites goto statements so that they can jump to labels in
+// blocks that compileDispatch turned into dispatch switches.
+//
+// A goto statement cannot jump to a label inside a block, which is what
+// labels become once the statements of their enclosing block have been
+// split into the case clauses of a dispatch switch. These labels are moved
+// to the dispatch switch itself, and goto statements are rewritten to set
+// the instruction pointer to the start of the labeled statement before
+// jumping, so that the switch dispatches to it:
+//
+//	goto L => { _f.IP = <start of L>; goto L }
+//
+// The instruction pointer is set even if the label was not moved, so that
+// the dispatch switches nested in the labeled statement restart from the
+// beginning.
+func compileGotos(body *ast.BlockStmt, frame *ast.Ident, dispatchSpans map[ast.Stmt]dispatchSpan) {
+	targets := map[string]int{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.FuncLit:
+			return false // labels are scoped to the function body
+		case *ast.BranchStmt:
+			if n.Tok == token.GOTO {
+				targets[n.Label.Name] = 0
+			}
+		}
+		return true
+	})
+	if len(targets) == 0 {
+		return
+	}
+
+	labels := map[*ast.SwitchStmt][]*ast.Ident{}
+	var stack []ast.Node
+	ast.Inspect(body, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if _, ok := node.(*ast.FuncLit); ok {
+			return false
+		}
+		stack = append(stack, node)
+
+		s, ok := node.(*ast.LabeledStmt)
+		if !ok {
+			return true
+		}
+		if _, ok := targets[s.Label.Name]; !ok {
+			return true
+		}
+		targets[s.Label.Name] = dispatchSpans[s].start
+
+		// The stack ends with: switch, switch body, case clause, label.
+		if len(stack) < 4 {
+			return true
+		}
+		caseClause, ok := stack[len(stack)-2].(*ast.CaseClause)
+		if !ok {
+			return true
+		}
+		switchStmt, ok := stack[len(stack)-4].(*ast.SwitchStmt)
+		if !ok || !isDispatchSwitch(switchStmt, frame) {
+			return true
+		}
+		for i, child := range caseClause.Body {
+			if child == s {
+				caseClause.Body[i] = s.Stmt
+			}
+		}
+		labels[switchStmt] = append(labels[switchStmt], s.Label)
+		return true
+	})
+
+	astutil.Apply(body, func(cursor *astutil.Cursor) bool {
+		_, ok := cursor.Node().(*ast.FuncLit)
+		return !ok
+	}, func(cursor *astutil.Cursor) bool {
+		switch n := cursor.Node().(type) {
+		case *ast.SwitchStmt:
+			var stmt ast.Stmt = n
+			for _, label := range labels[n] {
+				stmt = &ast.LabeledStmt{Label: label, Stmt: stmt}
+			}
+			if stmt != n {
+				cursor.Replace(stmt)
+			}
+		case *ast.BranchStmt:
+			if n.Tok != token.GOTO {
+				break
+			}
+			cursor.Replace(&ast.BlockStmt{List: []ast.Stmt{
+				&ast.AssignStmt{
+					Lhs: []ast.Expr{&ast.SelectorExpr{X: frame, Sel: ast.NewIdent("IP")}},
+					Tok: token.ASSIGN,
+					Rhs: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(targets[n.Label.Name])}},
+				},
+				n,
+			}})
+		}
+		return true
+	})
+}
+
+// isDispatchSwitch returns true if s is a switch statement generated by
+// compileDispatch0.
+func isDispatchSwitch(s *ast.SwitchStmt, frame *ast.Ident) bool {
+	if s.Init != nil || s.Tag != nil || len(s.Body.List) == 0 {
+		return false
+	}
+	c, ok := s.Body.List[0].(*ast.CaseClause)
+	if !ok || len(c.List) != 1 {
+		return false
+	}
+	cond, ok := c.List[0].(*ast.BinaryExpr)
+	if !ok || cond.Op != token.LSS {
+		return false
+	}
+	ip, ok := cond.X.(*ast.SelectorExpr)
+	return ok && ip.X == frame && ip.Sel.Name == "IP"
+}
@@ -0,0 +1,51 @@
+package compiler
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+)
+
+// writeDryRun writes the report of a dry run to w. For each package, in
+// order, the report lists the files that would be generated, then the
+// functions colored as coroutines with the R and S types of the yield
+// instantiation they were colored with.
+func writeDryRun(w io.Writer, pkgs []*packages.Package, colorsByPkg map[*packages.Package]functionColors, vendored []*packages.Package) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	for _, p := range pkgs {
+		if slices.Contains(vendored, p) {
+			fmt.Fprintf(tw, "package %s (vendored from GOROOT)\n", p.PkgPath)
+		} else {
+			fmt.Fprintf(tw, "package %s\n", p.PkgPath)
+		}
+
+		for _, path := range p.GoFiles {
+			fmt.Fprintf(tw, "\tfile\t%s\n", strings.TrimSuffix(path, ".go")+"_durable.go")
+		}
+
+		colors := colorsByPkg[p]
+		fns := make([]*ssa.Function, 0, len(colors))
+		for fn := range colors {
+			fns = append(fns, fn)
+		}
+		slices.SortFunc(fns, func(a, b *ssa.Function) int {
+			return strings.Compare(a.RelString(p.Types), b.RelString(p.Types))
+		})
+
+		qualifier := types.RelativeTo(p.Types)
+		for _, fn := range fns {
+			color := colors[fn]
+			fmt.Fprintf(tw, "\tcoroutine\t%s\tR=%s\tS=%s\n", fn.RelString(p.Types),
+				types.TypeString(color.Params().At(0).Type(), qualifier),
+				types.TypeString(color.Results().At(0).Type(), qualifier))
+		}
+	}
+	return tw.Flush()
+}
@@ -189,7 +189,7 @@ func collectFunctypes(p *packages.Package, name string, fn ast.Node, scope *func
 		}
 
 		for i, anonFunc := range anonFuncs[index:] {
-			anonFuncName := anonFuncLinkName(name, index+i+1)
+			anonFuncName := anonFuncLinkName(name, isFuncLit(fn), index+i+1)
 			collectFunctypes(p, anonFuncName, anonFunc.node, anonFunc.scope, colors, functypes)
 		}
 	}
@@ -277,11 +277,20 @@ func generateFunctypes(p *packages.Package, f *ast.File, colors map[ast.Node]*ty
 // using the base name of their parent function and appending ".func<index>".
 //
 // The function works with multiple levels of nesting as each level adds another
-// ".func<index>" suffix, with the index being local to the parent scope.
-func anonFuncLinkName(base string, index int) string {
+// suffix, with the index being local to the parent scope. Anonymous functions
+// nested in other anonymous functions only get a ".<index>" suffix.
+func anonFuncLinkName(base string, nested bool, index int) string {
+	if nested {
+		return fmt.Sprintf("%s.%d", base, index)
+	}
 	return fmt.Sprintf("%s.func%d", base, index)
 }
 
+func isFuncLit(fn ast.Node) bool {
+	_, ok := fn.(*ast.FuncLit)
+	return ok
+}
+
 func functionTypeOf(fn ast.Node) *ast.FuncType {
 	switch f := fn.(type) {
 	case *ast.FuncDecl:
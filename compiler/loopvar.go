@@ -0,0 +1,106 @@
+package compiler
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// loopVarCaptures returns the identifiers of loop variables captured by
+// function literals within a function body.
+//
+// Variables of coroutines are hoisted to the coroutine frame, which means
+// that a loop variable is shared by all iterations of the loop, like it was
+// the case before Go 1.22. Function literals that capture a loop variable and
+// are invoked after the iteration that created them (e.g. after a yield)
+// observe the value of the variable at that time rather than a per-iteration
+// copy, which is a common source of bugs. Function literals that are called
+// immediately, without defer or go, are not reported.
+func loopVarCaptures(body *ast.BlockStmt, info *types.Info) (captures []*ast.Ident) {
+	loopVars := map[types.Object]struct{}{}
+	addLoopVar := func(expr ast.Expr) {
+		if ident, ok := expr.(*ast.Ident); ok {
+			if obj := info.Defs[ident]; obj != nil {
+				loopVars[obj] = struct{}{}
+			}
+		}
+	}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ForStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok && init.Tok == token.DEFINE {
+				for _, lhs := range init.Lhs {
+					addLoopVar(lhs)
+				}
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.DEFINE {
+				addLoopVar(n.Key)
+				addLoopVar(n.Value)
+			}
+		}
+		return true
+	})
+
+	if len(loopVars) == 0 {
+		return nil
+	}
+
+	// Function literals called immediately cannot observe later iterations
+	// of the loop, unless the call is deferred or starts a goroutine.
+	immediate := map[*ast.FuncLit]struct{}{}
+	deferred := map[*ast.CallExpr]struct{}{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.DeferStmt:
+			deferred[n.Call] = struct{}{}
+		case *ast.GoStmt:
+			deferred[n.Call] = struct{}{}
+		case *ast.CallExpr:
+			if lit, ok := astutil.Unparen(n.Fun).(*ast.FuncLit); ok {
+				if _, ok := deferred[n]; !ok {
+					immediate[lit] = struct{}{}
+				}
+			}
+		}
+		return true
+	})
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		lit, ok := node.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		if _, ok := immediate[lit]; ok {
+			// Function literals nested in its body may still capture
+			// loop variables.
+			return true
+		}
+		seen := map[types.Object]struct{}{}
+		ast.Inspect(lit.Body, func(node ast.Node) bool {
+			ident, ok := node.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := info.Uses[ident]
+			if _, ok := loopVars[obj]; !ok {
+				return true
+			}
+			// Loop variables declared within the function literal are not
+			// captured.
+			if obj.Pos() >= lit.Pos() && obj.Pos() < lit.End() {
+				return true
+			}
+			if _, ok := seen[obj]; !ok {
+				seen[obj] = struct{}{}
+				captures = append(captures, ident)
+			}
+			return true
+		})
+		return false
+	})
+	return captures
+}
@@ -0,0 +1,80 @@
+package compiler
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestLoopVarCaptures(t *testing.T) {
+	src := `package foo
+
+func yield(int) {}
+
+func f() {
+	for i := 0; i < 3; i++ {
+		g := func() { yield(i); yield(i) }
+		yield(0)
+		g()
+	}
+	for _, v := range []int{1} {
+		func() { yield(v) }()
+		defer func() { yield(v) }()
+		go func() { yield(v) }()
+	}
+	for i := 0; i < 3; i++ {
+		j := i
+		func() { yield(j) }()
+		func() {
+			h := func() { yield(i) }
+			h()
+		}()
+	}
+	func() {
+		for k := 0; k < 1; k++ {
+			yield(k)
+		}
+	}()
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "foo.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Defs: map[*ast.Ident]types.Object{},
+		Uses: map[*ast.Ident]types.Object{},
+	}
+	if _, err := new(types.Config).Check("foo", fset, []*ast.File{f}, info); err != nil {
+		t.Fatal(err)
+	}
+
+	var body *ast.BlockStmt
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "f" {
+			body = fn.Body
+		}
+	}
+
+	var captures []string
+	for _, ident := range loopVarCaptures(body, info) {
+		captures = append(captures, fset.Position(ident.Pos()).String()+" "+ident.Name)
+	}
+	expect := []string{
+		"foo.go:7:23 i",
+		"foo.go:13:24 v",
+		"foo.go:14:21 v",
+		"foo.go:20:24 i",
+	}
+	if len(captures) != len(expect) {
+		t.Fatalf("unexpected captures: got %q, expect %q", captures, expect)
+	}
+	for i := range expect {
+		if captures[i] != expect[i] {
+			t.Errorf("unexpected capture: got %q, expect %q", captures[i], expect[i])
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package testdata
 
 import (
+	"sort"
 	"time"
 	"unsafe"
 
@@ -56,6 +57,16 @@ func NestedLoops(n int) int {
 	return count
 }
 
+func NestedLoopsYieldingAtEachLevel(n int) {
+	for i := 1; i <= n; i++ {
+		coroutine.Yield[int, any](-i)
+		for j := 1; j <= n; j++ {
+			coroutine.Yield[int, any](i*10 + j)
+		}
+		coroutine.Yield[int, any](i)
+	}
+}
+
 func FizzBuzzIfGenerator(n int) {
 	for i := 1; i <= n; i++ {
 		if i%3 == 0 && i%5 == 0 {
@@ -537,6 +548,15 @@ func (s *MethodGeneratorState) MethodGenerator(n int) {
 	}
 }
 
+type ValueMethodGeneratorState struct{ Base int }
+
+func (s ValueMethodGeneratorState) ValueMethodGenerator(n int) {
+	for i := 0; i < n; i++ {
+		coroutine.Yield[int, any](s.Base + i)
+		s.Base *= 2
+	}
+}
+
 func VarArgs(n int) {
 	args := make([]int, n)
 	for i := range args {
@@ -557,3 +577,663 @@ func ReturnNamedValue() (out int) {
 	out = 42
 	return
 }
+
+var rangeOverSliceCalls int
+
+func rangeOverSlice(n int) []int {
+	rangeOverSliceCalls++
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i * 10
+	}
+	return s
+}
+
+func RangeOverSliceFromCall(n int) {
+	rangeOverSliceCalls = 0
+	for _, v := range rangeOverSlice(n) {
+		coroutine.Yield[int, any](v)
+		// The range expression must be evaluated only once, even when
+		// the coroutine is resumed from a serialized state.
+		coroutine.Yield[int, any](rangeOverSliceCalls)
+	}
+}
+
+type point struct{ X, Y int }
+
+func YieldIndexAndSelectorExpressions(n int) {
+	data := []int{n, n * 2, n * 3}
+	arr := [2]int{n * 4, n * 5}
+	p := point{X: n * 6, Y: n * 7}
+	pp := &point{X: n * 8}
+	m := map[string]int{"k": n * 9}
+
+	coroutine.Yield[int, any](data[0])
+	coroutine.Yield[int, any](data[len(data)-1])
+	coroutine.Yield[int, any](arr[1])
+	coroutine.Yield[int, any](p.X)
+	p.Y++
+	coroutine.Yield[int, any](p.Y)
+	coroutine.Yield[int, any](pp.X)
+	coroutine.Yield[int, any](m["k"])
+	data[1] = -1
+	coroutine.Yield[int, any](data[1])
+}
+
+func SwitchInLoopContinue(n int) {
+	for i := 0; i < n; i++ {
+		switch {
+		case i%2 == 0:
+			coroutine.Yield[int, any](i)
+			continue
+		case i == 3:
+			coroutine.Yield[int, any](-i)
+			continue
+		}
+		coroutine.Yield[int, any](i * 10)
+	}
+
+	j := 0
+	for {
+		switch j {
+		case 0, 1:
+			j++
+			coroutine.Yield[int, any](j)
+			continue
+		}
+		break
+	}
+}
+
+func deferredCounter(counter *int) {
+	defer func() { *counter = *counter*10 + 1 }()
+	coroutine.Yield[int, any](*counter)
+	defer func() { *counter = *counter*10 + 2 }()
+	coroutine.Yield[int, any](*counter)
+}
+
+func YieldAcrossDefers(n int) {
+	counter := 0
+	for i := 0; i < n; i++ {
+		// Deferred functions run once, in LIFO order, when the function
+		// returns, even if it was resumed in between.
+		deferredCounter(&counter)
+		coroutine.Yield[int, any](counter)
+	}
+}
+
+func SelectSendRecvDefault(n int) {
+	ch := make(chan int, 1)
+	for i := 0; i < n; i++ {
+		select {
+		case ch <- i + 1:
+			coroutine.Yield[int, any](i + 1)
+		case v := <-ch:
+			coroutine.Yield[int, any](-v)
+		}
+	}
+
+	select {
+	case <-ch:
+		panic("unreachable")
+	default:
+		coroutine.Yield[int, any](100)
+	}
+
+	ch <- 200
+	select {
+	case v, ok := <-ch:
+		if ok {
+			coroutine.Yield[int, any](v)
+		}
+	default:
+		panic("unreachable")
+	}
+}
+
+func YieldDepth() {
+	yieldDepth()
+	yieldDepth2()
+	yieldDepth()
+}
+
+func yieldDepth() {
+	coroutine.Yield[int, any](coroutine.LoadContext[int, any]().Depth())
+}
+
+func yieldDepth2() {
+	yieldDepth()
+	yieldDepth3()
+	yieldDepth()
+}
+
+func yieldDepth3() {
+	yieldDepth()
+	yieldDepth()
+}
+
+func TypeSwitchYieldInCase(values ...any) {
+	for _, value := range values {
+		switch v := value.(type) {
+		case int:
+			coroutine.Yield[int, any](v)
+			// The bound variable must survive the yield.
+			coroutine.Yield[int, any](v * 10)
+		case string:
+			coroutine.Yield[int, any](len(v))
+			coroutine.Yield[int, any](len(v + v))
+		case nil:
+			coroutine.Yield[int, any](-1)
+		case []int, [2]int:
+			coroutine.Yield[int, any](-2)
+			_ = v
+		default:
+			coroutine.Yield[int, any](-3)
+		}
+	}
+}
+
+func Goto(n int) {
+	i := 0
+retry:
+	coroutine.Yield[int, any](i)
+	if i++; i < n {
+		goto retry
+	}
+
+	for j := 0; j < n; j++ {
+		if j%2 == 0 {
+			goto skip
+		}
+		{
+			x := j * 10
+			coroutine.Yield[int, any](x)
+		}
+	skip:
+		coroutine.Yield[int, any](-j)
+	}
+
+	k := 0
+loop:
+	for m := 0; m < 2; m++ {
+		if m < 0 {
+			continue loop
+		}
+		coroutine.Yield[int, any](100 + k*10 + m)
+	}
+	if k++; k < 2 {
+		goto loop
+	}
+}
+
+func SwitchFallthrough(n int) {
+	for i := 0; i < n; i++ {
+		switch i {
+		case 0:
+			coroutine.Yield[int, any](0)
+			fallthrough
+		case 1:
+			coroutine.Yield[int, any](1)
+		case 2:
+			coroutine.Yield[int, any](2)
+			fallthrough
+		default:
+			coroutine.Yield[int, any](3)
+			if i > 4 {
+				break
+			}
+			fallthrough
+		case 4:
+			coroutine.Yield[int, any](4)
+		}
+	}
+}
+
+func LocalTypeDeclarations(n int) {
+	type pair struct{ a, b int }
+	type pairs []pair
+	type celsius = int
+
+	var ps pairs
+	for i := 0; i < n; i++ {
+		ps = append(ps, pair{i, i * 10})
+		var c celsius = 100 + i
+		coroutine.Yield[int, any](c)
+	}
+	for _, p := range ps {
+		coroutine.Yield[int, any](p.a + p.b)
+	}
+}
+
+func NestedRangeContinueOuter(rows [][]int) {
+rows:
+	for i, row := range rows {
+		for _, v := range row {
+			coroutine.Yield[int, any](v)
+			if v < 0 {
+				continue rows
+			}
+			if v == 0 {
+				break rows
+			}
+		}
+		coroutine.Yield[int, any](100 + i)
+	}
+}
+
+func InlineClosureGenerators(n int) {
+	gen := func(start, step int) func() {
+		next := start
+		coroutine.Yield[int, any](-start)
+		return func() {
+			coroutine.Yield[int, any](next)
+			next += step
+		}
+	}
+	evens, odds := gen(0, 2), gen(1, 2)
+	for i := 0; i < n; i++ {
+		evens()
+		odds()
+	}
+	evens()
+}
+
+type resource struct {
+	open   bool
+	closed int
+}
+
+func (r *resource) close() {
+	r.closed++
+	r.open = false
+	coroutine.Yield[int, any](-r.closed)
+}
+
+func ReturnAfterCleanup(n int) int {
+	r := &resource{open: true}
+	for i := 0; i < n; i++ {
+		coroutine.Yield[int, any](i)
+		if i == 2 {
+			r.close()
+			return 100*r.closed + i
+		}
+	}
+	r.close()
+	return 100 * r.closed
+}
+
+// commaOkChannel is held in a package variable so it is not stored in the
+// coroutine frames, which do not support serializing channels yet.
+var commaOkChannel chan int
+
+func divmod(a, b int) (int, int) {
+	return a / b, a % b
+}
+
+func CommaOkAssignments(n int) {
+	commaOkChannel = make(chan int, n)
+	for i := 0; i < n; i++ {
+		commaOkChannel <- i * 10
+	}
+	close(commaOkChannel)
+
+	for {
+		v, ok := <-commaOkChannel
+		coroutine.Yield[int, any](v)
+		if !ok {
+			break
+		}
+	}
+
+	m := map[int]int{1: 100}
+	a, found := m[1]
+	b, missing := m[2]
+	coroutine.Yield[int, any](a)
+	if found && !missing {
+		coroutine.Yield[int, any](b)
+	}
+
+	var x any = 42
+	i, isInt := x.(int)
+	s, isString := x.(string)
+	coroutine.Yield[int, any](i)
+	if isInt && !isString {
+		coroutine.Yield[int, any](len(s))
+	}
+
+	q, r := divmod(17, 5)
+	coroutine.Yield[int, any](q)
+	coroutine.Yield[int, any](r)
+	q, r = r, q
+	coroutine.Yield[int, any](q)
+	coroutine.Yield[int, any](r)
+}
+
+func AssignToFieldsIndexesAndPointers(n int) {
+	buf := make([]int, n)
+	arr := [2]point{}
+	p := &arr[1]
+	m := map[int]int{}
+	pm := &m
+	for i := 0; i < n; i++ {
+		x := i * i
+		buf[i] = x
+		arr[0].X += x
+		p.Y = i
+		(*pm)[i%2] += x
+		coroutine.Yield[int, any](buf[i])
+	}
+	*p = point{X: arr[0].X, Y: p.Y * 10}
+	coroutine.Yield[int, any](arr[0].X)
+	coroutine.Yield[int, any](arr[1].X)
+	coroutine.Yield[int, any](arr[1].Y)
+	coroutine.Yield[int, any](m[0])
+	coroutine.Yield[int, any](m[1])
+	for _, v := range buf {
+		coroutine.Yield[int, any](v)
+	}
+}
+
+func RangeOverMapDeletingKeys(n int) {
+	m := make(map[int]int, n)
+	for i := 0; i < n; i++ {
+		m[i] = i
+	}
+	iterations := 0
+	for k, v := range m {
+		// Deleting the pair of the key removes an entry that may not have
+		// been reached yet, which must then not be produced.
+		delete(m, k)
+		delete(m, v^1)
+		iterations++
+		coroutine.Yield[int, any](len(m))
+	}
+	coroutine.Yield[int, any](iterations)
+}
+
+type runes string
+
+func RangeOverStrings(s string) {
+	for i, r := range s {
+		coroutine.Yield[int, any](i)
+		coroutine.Yield[int, any](int(r))
+	}
+	n := 0
+	for range s {
+		n++
+		coroutine.Yield[int, any](n)
+	}
+	for i := range runes(s) {
+		coroutine.Yield[int, any](i)
+	}
+	for _, r := range "ü!" {
+		coroutine.Yield[int, any](int(r))
+	}
+}
+
+func ClosureCapturingLoopIndex(n int) {
+	var fns []func()
+	for i := 0; i < n; i++ {
+		fns = append(fns, func() { coroutine.Yield[int, any](i) })
+		fns[i]()
+	}
+	// The loop variable is shared by all iterations, so the closures
+	// observe its final value.
+	for _, f := range fns {
+		f()
+	}
+}
+
+func RangeOverChannel(n int) {
+	ch := make(chan int, 1)
+	ch <- 0
+	for v := range ch {
+		coroutine.Yield[int, any](v)
+		if v < n {
+			ch <- v + 1
+		} else {
+			close(ch)
+		}
+	}
+	coroutine.Yield[int, any](-1)
+}
+
+func InlineVarAndConstDeclarations(n int) {
+	const step = 10
+	var buf []byte
+	for i := 0; i < n; i++ {
+		var sum int
+		var (
+			scaled = i * step
+			label  string
+		)
+		sum += scaled
+		label += "x"
+		buf = append(buf, byte(sum))
+		coroutine.Yield[int, any](sum + len(label))
+	}
+	coroutine.Yield[int, any](len(buf))
+	for _, b := range buf {
+		coroutine.Yield[int, any](int(b))
+	}
+}
+
+func StructSendGenerator(n int) {
+	for i := 0; i < n; i++ {
+		coroutine.Yield[int, struct{}](i * i)
+	}
+}
+
+func YieldInExpression(n int) {
+	// The values sent back to the coroutine are used directly in
+	// expressions, including as arguments of other function calls.
+	x := coroutine.Yield[int, int](n) + 1
+	y := double(coroutine.Yield[int, int](x)) * coroutine.Yield[int, int](x+1)
+	coroutine.Yield[int, int](y)
+}
+
+func RunningTotal(n int) {
+	// Each value sent back to the coroutine is added to the total, which is
+	// yielded after every step.
+	total := 0
+	for i := 0; i < n; i++ {
+		total += coroutine.Yield[int, int](total)
+	}
+	coroutine.Yield[int, int](total)
+}
+
+func SumSentValues(n int) int {
+	// The coroutine yields the index of each step, and returns the sum of
+	// the values sent back to it.
+	sum := 0
+	for i := 0; i < n; i++ {
+		sum += coroutine.Yield[int, int](i)
+	}
+	return sum
+}
+
+// CancelCleanups counts the runs of the function deferred by
+// CountUntilCancelled, which only returns by being cancelled.
+var CancelCleanups int
+
+func CountUntilCancelled() {
+	defer func() { CancelCleanups++ }()
+	for i := 0; ; i++ {
+		coroutine.Yield[int, any](i)
+	}
+}
+
+func CancelSelfAndReturn(n int) {
+	// The coroutine is cancelled, but returns without reaching another
+	// yield point.
+	coroutine.Yield[int, any](n)
+	coroutine.LoadContext[int, any]().Cancel()
+}
+
+func RecursiveYield(n int) {
+	// Each call pushes its own frame on the coroutine stack, so the state
+	// of every level of the recursion is restored when resuming.
+	if n == 0 {
+		return
+	}
+	coroutine.Yield[int, any](n)
+	RecursiveYield(n - 1)
+	coroutine.Yield[int, any](-n)
+}
+
+func MutuallyRecursiveYield(n int) {
+	if n > 0 {
+		coroutine.Yield[int, any](n)
+		mutuallyRecursiveYield(n - 1)
+	}
+}
+
+func mutuallyRecursiveYield(n int) {
+	if n > 0 {
+		coroutine.Yield[int, any](n * 10)
+		MutuallyRecursiveYield(n - 1)
+		coroutine.Yield[int, any](n * 100)
+	}
+}
+
+func SortWithClosure(n int) {
+	// Function literals that don't yield are passed through to functions
+	// that are not coroutines, like sort.Slice.
+	s := make([]int, n)
+	for i := range s {
+		s[i] = n - i
+		coroutine.Yield[int, any](s[i])
+	}
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	for _, v := range s {
+		coroutine.Yield[int, any](v * 10)
+	}
+}
+
+func yieldAndReturn(v int) int {
+	coroutine.Yield[int, any](v)
+	return v
+}
+
+func incrementAndYield(p *int) int {
+	*p++
+	coroutine.Yield[int, any](*p)
+	return *p
+}
+
+func double(v int) int { return 2 * v }
+
+func MultipleCallsInExpression(n int) {
+	// Calls must be evaluated from left to right, including when only some
+	// of them may yield.
+	a := yieldAndReturn(1) + yieldAndReturn(2)
+	coroutine.Yield[int, any](a)
+	b := double(n) + incrementAndYield(&n)
+	coroutine.Yield[int, any](b)
+	c := double(yieldAndReturn(n)) * yieldAndReturn(double(n))
+	coroutine.Yield[int, any](c)
+}
+
+func SwitchWithInitStatement(n int) {
+	// The tag is evaluated once, and the variable declared by the init
+	// statement is restored when resuming within a case.
+	switch x := yieldAndReturn(n); incrementAndYield(&x) {
+	case n:
+		panic("unreachable")
+	case n + 1:
+		coroutine.Yield[int, any](x)
+		coroutine.Yield[int, any](x * 10)
+	default:
+		panic("unreachable")
+	}
+
+	switch y := yieldAndReturn(-n); {
+	case y > 0:
+		panic("unreachable")
+	case y < 0:
+		coroutine.Yield[int, any](y * 10)
+		coroutine.Yield[int, any](y)
+	}
+}
+
+func ForLoopWithMultiplePostAssignments(n int) {
+	for i, j := 0, n; i < j; i, j = i+1, j-1 {
+		coroutine.Yield[int, any](i*10 + j)
+	}
+}
+
+func ForLoopWithMultipleInitVariables(s []int) {
+	// Both variables declared by the init statement are restored when
+	// resuming within the loop body.
+	for i, j := yieldAndReturn(0), len(s)-1; i < j; i, j = i+1, j-1 {
+		coroutine.Yield[int, any](s[i])
+		coroutine.Yield[int, any](s[j])
+	}
+}
+
+func ForLoopWithYieldingPostStatement(n int) {
+	// The post iteration statement is executed after continue statements,
+	// and may yield.
+	for i := 0; i < n; i = yieldAndReturn(i*10)/10 + 1 {
+		if i%2 == 1 {
+			continue
+		}
+		coroutine.Yield[int, any](i)
+	}
+}
+
+func ForLoopWithPostStatementAndLabeledContinue(n int) {
+outer:
+	for i := 0; i < n; i = double(i) + 1 {
+		for j := 0; j < n; j++ {
+			if j > i {
+				continue outer
+			}
+			coroutine.Yield[int, any](i*10 + j)
+		}
+	}
+}
+
+func ShortCircuitEvaluation(n int) {
+	// The right operand of && and || is only evaluated when the left operand
+	// does not determine the result, which is observable since it yields.
+	if n > 0 && yieldAndReturn(n) == n {
+		coroutine.Yield[int, any](1)
+	}
+	if n < 0 && yieldAndReturn(-1) == -1 {
+		panic("unreachable")
+	}
+	if n < 0 || yieldAndReturn(n*10) == n*10 {
+		coroutine.Yield[int, any](2)
+	}
+	if n > 0 || yieldAndReturn(-2) == -2 {
+		coroutine.Yield[int, any](3)
+	}
+	v := yieldAndReturn(n) > 0 && yieldAndReturn(n*100) > 0 && n < 0 || yieldAndReturn(4) > 0
+	if v {
+		coroutine.Yield[int, any](5)
+	}
+}
+
+func recoverPanic(n int) (v int) {
+	defer func() {
+		if r := recover(); r != nil {
+			v = r.(int)
+		}
+	}()
+	coroutine.Yield[int, any](n)
+	panic(n * 10)
+}
+
+func RecoverAfterYield(n int) {
+	for i := 0; i < n; i++ {
+		coroutine.Yield[int, any](recoverPanic(i))
+	}
+}
+
+func YieldWhilePanicking() {
+	defer func() {
+		coroutine.Yield[int, any](1)
+	}()
+	panic("boom")
+}
@@ -1,13 +1,22 @@
 //go:build durable
 
+// Code generated by coroc. DO NOT EDIT.
+//
+// This file holds the durable version of coroutine.go. It is only built
+// with -tags durable, and the original source file is only built without
+// it (//go:build !durable), so the package compiles in both modes.
+
 package testdata
 
 import (
-	coroutine "github.com/stealthrocket/coroutine"
+	sort "sort"
 	time "time"
+	utf8 "unicode/utf8"
 	unsafe "unsafe"
+
+	coroutine "github.com/stealthrocket/coroutine"
+	_types "github.com/stealthrocket/coroutine/types"
 )
-import _types "github.com/stealthrocket/coroutine/types"
 
 func SomeFunctionThatShouldExistInTheCompiledFile() {
 }
@@ -201,6 +210,7 @@ func NestedLoops(_fn0 int) (_ int) {
 	}()
 	switch {
 	case _f0.IP < 2:
+		_f0.X1 = *new(int)
 		_f0.IP = 2
 		fallthrough
 	case _f0.IP < 7:
@@ -248,6 +258,66 @@ func NestedLoops(_fn0 int) (_ int) {
 	panic("unreachable")
 }
 
+//go:noinline
+func NestedLoopsYieldingAtEachLevel(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 1
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 6:
+		for ; _f0.X1 <= _f0.X0; _f0.X1, _f0.IP = _f0.X1+1, 2 {
+			switch {
+			case _f0.IP < 3:
+				coroutine.Yield[int, any](-_f0.X1)
+				_f0.IP = 3
+				fallthrough
+			case _f0.IP < 5:
+				switch {
+				case _f0.IP < 4:
+					_f0.X2 = 1
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 5:
+					for ; _f0.X2 <= _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 4 {
+						coroutine.Yield[int, any](_f0.X1*10 + _f0.X2)
+					}
+				}
+				_f0.IP = 5
+				fallthrough
+			case _f0.IP < 6:
+
+				coroutine.Yield[int, any](_f0.X1)
+			}
+		}
+	}
+}
+
 //go:noinline
 func FizzBuzzIfGenerator(_fn0 int) {
 	_c := coroutine.LoadContext[int, any]()
@@ -397,6 +467,22 @@ func FizzBuzzSwitchGenerator(_fn0 int) {
 //go:noinline
 func Shadowing(_ int) {
 	_c := coroutine.LoadContext[int, any]()
+
+	const _o0 = 11
+
+	const _o1 = 12
+
+	type _o2 uint16
+
+	type _o3 uint32
+
+	const _o4 = 1
+	type _o5 [_o4]uint8
+
+	type _o6 [_o4]uint8
+
+	const _o7 = unsafe.Sizeof(_o6{}) * 2
+	type _o8 [_o7]uint8
 	var _f0 *struct {
 		IP  int
 		X0  int
@@ -448,22 +534,6 @@ func Shadowing(_ int) {
 		X21 uintptr
 		X22 int
 	}](&_c.Stack)
-
-	const _o0 = 11
-
-	const _o1 = 12
-
-	type _o2 uint16
-
-	type _o3 uint32
-
-	const _o4 = 1
-	type _o5 [_o4]uint8
-
-	type _o6 [_o4]uint8
-
-	const _o7 = unsafe.Sizeof(_o6{}) * 2
-	type _o8 [_o7]uint8
 	if _f0.IP == 0 {
 		*_f0 = struct {
 			IP  int
@@ -1894,6 +1964,7 @@ func Range10ClosureHeterogenousCapture() {
 			}()
 			switch {
 			case _f0.IP < 2:
+				_f0.X0 = *new(int)
 				_f0.IP = 2
 				fallthrough
 			case _f0.IP < 13:
@@ -2054,10 +2125,10 @@ func Range10Heterogenous() {
 			for ; _f0.X9 < 10; _f0.X9, _f0.IP = _f0.X9+1, 11 {
 				switch {
 				case _f0.IP < 12:
+					_f0.X10 = *new(int)
 					_f0.IP = 12
 					fallthrough
 				case _f0.IP < 22:
-
 					switch _f0.X9 {
 					case 0:
 						_f0.X10 = int(_f0.X0)
@@ -3008,9 +3079,7 @@ func YieldAndDeferAssign(_fn0 *int, _fn1, _fn2 int) {
 	defer func() {
 		if !_c.Unwinding() {
 			defer coroutine.Pop(&_c.Stack)
-			for _, f := range _f0.X3 {
-				defer f()
-			}
+			_c.RunDefers(_f0.X3, recover())
 		}
 	}()
 	switch {
@@ -3100,6 +3169,55 @@ func (_fn0 *MethodGeneratorState) MethodGenerator(_fn1 int) {
 	}
 }
 
+type ValueMethodGeneratorState struct{ Base int }
+
+//go:noinline
+func (_fn0 ValueMethodGeneratorState) ValueMethodGenerator(_fn1 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 ValueMethodGeneratorState
+		X1 int
+		X2 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 ValueMethodGeneratorState
+		X1 int
+		X2 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 ValueMethodGeneratorState
+			X1 int
+			X2 int
+		}{X0: _fn0, X1: _fn1}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X2 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 4:
+		for ; _f0.X2 < _f0.X1; _f0.X2, _f0.IP = _f0.X2+1, 2 {
+			switch {
+			case _f0.IP < 3:
+				coroutine.Yield[int, any](_f0.X0.Base + _f0.X2)
+				_f0.IP = 3
+				fallthrough
+			case _f0.IP < 4:
+				_f0.X0.
+					Base *= 2
+			}
+		}
+	}
+}
+
 //go:noinline
 func VarArgs(_fn0 int) {
 	_c := coroutine.LoadContext[int, any]()
@@ -3236,55 +3354,4217 @@ func ReturnNamedValue() (_fn0 int) {
 	}
 	panic("unreachable")
 }
-func init() {
-	_types.RegisterFunc[func(n int)]("github.com/stealthrocket/coroutine/compiler/testdata.Double")
-	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.EvenSquareGenerator")
-	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.FizzBuzzIfGenerator")
-	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.FizzBuzzSwitchGenerator")
-	_types.RegisterFunc[func(n int)]("github.com/stealthrocket/coroutine/compiler/testdata.Identity")
-	_types.RegisterFunc[func(_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.LoopBreakAndContinue")
-	_types.RegisterFunc[func(_fn1 int)]("github.com/stealthrocket/coroutine/compiler/testdata.MethodGenerator")
-	_types.RegisterFunc[func(_fn0 int) (_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.NestedLoops")
-	_types.RegisterFunc[func(_fn0 int, _fn1 func(int))]("github.com/stealthrocket/coroutine/compiler/testdata.Range")
-	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureCapturingPointers")
-	_types.RegisterClosure[func() (_ bool), struct {
-		F  uintptr
-		X0 *struct {
+
+var rangeOverSliceCalls int
+
+func rangeOverSlice(n int) []int {
+	rangeOverSliceCalls++
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i * 10
+	}
+	return s
+}
+
+//go:noinline
+func RangeOverSliceFromCall(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 []int
+		X2 int
+		X3 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 []int
+		X2 int
+		X3 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
 			IP int
 			X0 int
-			X1 int
-			X2 *int
-			X3 *int
-			X4 func() bool
-			X5 bool
-			X6 bool
+			X1 []int
+			X2 int
+			X3 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
 		}
-	}]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureCapturingPointers.func2")
-	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureCapturingValues")
-	_types.RegisterClosure[func() (_ bool), struct {
-		F  uintptr
-		X0 *struct {
+	}()
+	switch {
+	case _f0.IP < 2:
+		rangeOverSliceCalls = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 7:
+		switch {
+		case _f0.IP < 3:
+			_f0.X1 = rangeOverSlice(_f0.X0)
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 7:
+			switch {
+			case _f0.IP < 4:
+				_f0.X2 = 0
+				_f0.IP = 4
+				fallthrough
+			case _f0.IP < 7:
+				for ; _f0.X2 < len(_f0.X1); _f0.X2, _f0.IP = _f0.X2+1, 4 {
+					switch {
+					case _f0.IP < 5:
+						_f0.X3 = _f0.X1[_f0.X2]
+						_f0.IP = 5
+						fallthrough
+					case _f0.IP < 6:
+						coroutine.Yield[int, any](_f0.X3)
+						_f0.IP = 6
+						fallthrough
+					case _f0.IP < 7:
+
+						coroutine.Yield[int, any](rangeOverSliceCalls)
+					}
+				}
+			}
+		}
+	}
+}
+
+type point struct{ X, Y int }
+
+//go:noinline
+func YieldIndexAndSelectorExpressions(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 []int
+		X2 [2]int
+		X3 point
+		X4 *point
+		X5 map[string]int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 []int
+		X2 [2]int
+		X3 point
+		X4 *point
+		X5 map[string]int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
 			IP int
 			X0 int
-			X1 int
-			X2 func() bool
-			X3 bool
-			X4 bool
+			X1 []int
+			X2 [2]int
+			X3 point
+			X4 *point
+			X5 map[string]int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
 		}
-	}]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureCapturingValues.func2")
-	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureHeterogenousCapture")
-	_types.RegisterClosure[func() int, struct {
-		F  uintptr
-		X0 *struct {
-			IP  int
-			X0  int8
-			X1  int16
-			X2  int32
-			X3  int64
-			X4  uint8
-			X5  uint16
-			X6  uint32
-			X7  uint64
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = []int{_f0.X0, _f0.X0 * 2, _f0.X0 * 3}
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X2 = [2]int{_f0.X0 * 4, _f0.X0 * 5}
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		_f0.X3 = point{X: _f0.X0 * 6, Y: _f0.X0 * 7}
+		_f0.IP = 4
+		fallthrough
+	case _f0.IP < 5:
+		_f0.X4 = &point{X: _f0.X0 * 8}
+		_f0.IP = 5
+		fallthrough
+	case _f0.IP < 6:
+		_f0.X5 = map[string]int{"k": _f0.X0 * 9}
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 7:
+
+		coroutine.Yield[int, any](_f0.X1[0])
+		_f0.IP = 7
+		fallthrough
+	case _f0.IP < 8:
+		coroutine.Yield[int, any](_f0.X1[len(_f0.X1)-1])
+		_f0.IP = 8
+		fallthrough
+	case _f0.IP < 9:
+		coroutine.Yield[int, any](_f0.X2[1])
+		_f0.IP = 9
+		fallthrough
+	case _f0.IP < 10:
+		coroutine.Yield[int, any](_f0.X3.X)
+		_f0.IP = 10
+		fallthrough
+	case _f0.IP < 11:
+		_f0.X3.
+			Y++
+		_f0.IP = 11
+		fallthrough
+	case _f0.IP < 12:
+		coroutine.Yield[int, any](_f0.X3.Y)
+		_f0.IP = 12
+		fallthrough
+	case _f0.IP < 13:
+		coroutine.Yield[int, any](_f0.X4.X)
+		_f0.IP = 13
+		fallthrough
+	case _f0.IP < 14:
+		coroutine.Yield[int, any](_f0.X5["k"])
+		_f0.IP = 14
+		fallthrough
+	case _f0.IP < 15:
+		_f0.X1[1] = -1
+		_f0.IP = 15
+		fallthrough
+	case _f0.IP < 16:
+		coroutine.Yield[int, any](_f0.X1[1])
+	}
+}
+
+//go:noinline
+func SwitchInLoopContinue(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 bool
+		X3 bool
+		X4 int
+		X5 int
+		X6 bool
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 bool
+		X3 bool
+		X4 int
+		X5 int
+		X6 bool
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 bool
+			X3 bool
+			X4 int
+			X5 int
+			X6 bool
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 9:
+		switch {
+		case _f0.IP < 2:
+			_f0.X1 = 0
+			_f0.IP = 2
+			fallthrough
+		case _f0.IP < 9:
+		_l0:
+			for ; _f0.X1 < _f0.X0; _f0.X1, _f0.IP = _f0.X1+1, 2 {
+				switch {
+				case _f0.IP < 8:
+					switch {
+					default:
+						switch {
+						case _f0.IP < 3:
+							_f0.X2 = _f0.X1%
+								2 == 0
+							_f0.IP = 3
+							fallthrough
+						case _f0.IP < 8:
+							if _f0.X2 {
+								switch {
+								case _f0.IP < 4:
+									coroutine.Yield[int, any](_f0.X1)
+									_f0.IP = 4
+									fallthrough
+								case _f0.IP < 5:
+									continue _l0
+								}
+							} else {
+								switch {
+								case _f0.IP < 6:
+									_f0.X3 = _f0.X1 ==
+										3
+									_f0.IP = 6
+									fallthrough
+								case _f0.IP < 8:
+									if _f0.X3 {
+										switch {
+										case _f0.IP < 7:
+											coroutine.Yield[int, any](-_f0.X1)
+											_f0.IP = 7
+											fallthrough
+										case _f0.IP < 8:
+											continue _l0
+										}
+									}
+								}
+							}
+						}
+					}
+					_f0.IP = 8
+					fallthrough
+				case _f0.IP < 9:
+
+					coroutine.Yield[int, any](_f0.X1 * 10)
+				}
+			}
+		}
+		_f0.IP = 9
+		fallthrough
+	case _f0.IP < 10:
+		_f0.X4 = 0
+		_f0.IP = 10
+		fallthrough
+	case _f0.IP < 16:
+	_l2:
+		for ; ; _f0.IP = 10 {
+			switch {
+			case _f0.IP < 15:
+				switch {
+				case _f0.IP < 11:
+					_f0.X5 = _f0.X4
+					_f0.IP = 11
+					fallthrough
+				case _f0.IP < 15:
+					switch {
+					default:
+						switch {
+						case _f0.IP < 12:
+							_f0.X6 = _f0.X5 ==
+
+								0 || _f0.X5 == 1
+							_f0.IP = 12
+							fallthrough
+						case _f0.IP < 15:
+							if _f0.X6 {
+								switch {
+								case _f0.IP < 13:
+									_f0.X4++
+									_f0.IP = 13
+									fallthrough
+								case _f0.IP < 14:
+									coroutine.Yield[int, any](_f0.X4)
+									_f0.IP = 14
+									fallthrough
+								case _f0.IP < 15:
+									continue _l2
+								}
+							}
+						}
+					}
+				}
+				_f0.IP = 15
+				fallthrough
+			case _f0.IP < 16:
+				break _l2
+			}
+		}
+	}
+}
+
+//go:noinline
+func deferredCounter(_fn0 *int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 *int
+		X1 []func()
+	} = coroutine.Push[struct {
+		IP int
+		X0 *int
+		X1 []func()
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 *int
+			X1 []func()
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			defer coroutine.Pop(&_c.Stack)
+			_c.RunDefers(_f0.X1, recover())
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = append(_f0.X1, func() { *_f0.X0 = *_f0.X0*10 + 1 })
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		coroutine.Yield[int, any](*_f0.X0)
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		_f0.X1 = append(_f0.X1, func() { *_f0.X0 = *_f0.X0*10 + 2 })
+		_f0.IP = 4
+		fallthrough
+	case _f0.IP < 5:
+		coroutine.Yield[int, any](*_f0.X0)
+	}
+}
+
+//go:noinline
+func YieldAcrossDefers(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 5:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 5:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+
+					deferredCounter(&_f0.X1)
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 5:
+					coroutine.Yield[int, any](_f0.X1)
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func SelectSendRecvDefault(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP  int
+		X0  int
+		X1  chan int
+		X2  int
+		X3  int
+		X4  chan int
+		X5  int
+		X6  chan int
+		X7  int
+		X8  int
+		X9  bool
+		X10 bool
+		X11 int
+		X12 int
+		X13 chan int
+		X14 int
+		X15 bool
+		X16 bool
+		X17 int
+		X18 chan int
+		X19 int
+		X20 bool
+		X21 int
+		X22 bool
+		X23 int
+		X24 bool
+		X25 bool
+	} = coroutine.Push[struct {
+		IP  int
+		X0  int
+		X1  chan int
+		X2  int
+		X3  int
+		X4  chan int
+		X5  int
+		X6  chan int
+		X7  int
+		X8  int
+		X9  bool
+		X10 bool
+		X11 int
+		X12 int
+		X13 chan int
+		X14 int
+		X15 bool
+		X16 bool
+		X17 int
+		X18 chan int
+		X19 int
+		X20 bool
+		X21 int
+		X22 bool
+		X23 int
+		X24 bool
+		X25 bool
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP  int
+			X0  int
+			X1  chan int
+			X2  int
+			X3  int
+			X4  chan int
+			X5  int
+			X6  chan int
+			X7  int
+			X8  int
+			X9  bool
+			X10 bool
+			X11 int
+			X12 int
+			X13 chan int
+			X14 int
+			X15 bool
+			X16 bool
+			X17 int
+			X18 chan int
+			X19 int
+			X20 bool
+			X21 int
+			X22 bool
+			X23 int
+			X24 bool
+			X25 bool
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = make(chan int, 1)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 16:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 16:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					_f0.X3 = 0
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 5:
+					_f0.X4 = _f0.X1
+					_f0.IP = 5
+					fallthrough
+				case _f0.IP < 6:
+					_f0.X5 = _f0.X2 +
+						1
+					_f0.IP = 6
+					fallthrough
+				case _f0.IP < 7:
+					_f0.X6 = _f0.X1
+					_f0.IP = 7
+					fallthrough
+				case _f0.IP < 8:
+					_f0.X7 = *new(int)
+					_f0.IP = 8
+					fallthrough
+				case _f0.IP < 10:
+					select {
+					case _f0.X4 <- _f0.X5:
+						_f0.X3 = 1
+					case _f0.X7 = <-_f0.X6:
+						_f0.X3 = 2
+					}
+					_f0.IP = 10
+					fallthrough
+				case _f0.IP < 16:
+					switch {
+					case _f0.IP < 11:
+						_f0.X8 = _f0.X3
+						_f0.IP = 11
+						fallthrough
+					case _f0.IP < 16:
+						switch {
+						default:
+							switch {
+							case _f0.IP < 12:
+								_f0.X9 = _f0.X8 == 1
+								_f0.IP = 12
+								fallthrough
+							case _f0.IP < 16:
+								if _f0.X9 {
+									coroutine.Yield[int, any](_f0.X2 + 1)
+								} else {
+									switch {
+									case _f0.IP < 14:
+										_f0.X10 = _f0.X8 == 2
+										_f0.IP = 14
+										fallthrough
+									case _f0.IP < 16:
+										if _f0.X10 {
+											switch {
+											case _f0.IP < 15:
+												_f0.X11 = _f0.X7
+												_f0.IP = 15
+												fallthrough
+											case _f0.IP < 16:
+
+												coroutine.Yield[int, any](-_f0.X11)
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		_f0.IP = 16
+		fallthrough
+	case _f0.IP < 23:
+		switch {
+		case _f0.IP < 17:
+			_f0.X12 = 0
+			_f0.IP = 17
+			fallthrough
+		case _f0.IP < 18:
+			_f0.X13 = _f0.X1
+			_f0.IP = 18
+			fallthrough
+		case _f0.IP < 20:
+			select {
+			case <-_f0.X13:
+				_f0.X12 = 1
+			default:
+				_f0.X12 = 2
+			}
+			_f0.IP = 20
+			fallthrough
+		case _f0.IP < 23:
+			switch {
+			case _f0.IP < 21:
+				_f0.X14 = _f0.X12
+				_f0.IP = 21
+				fallthrough
+			case _f0.IP < 23:
+				switch {
+				default:
+					if _f0.X15 = _f0.X14 == 1; _f0.X15 {
+						panic("unreachable")
+					} else if _f0.X16 = _f0.X14 == 2; _f0.X16 {
+
+						coroutine.Yield[int, any](100)
+					}
+				}
+			}
+		}
+		_f0.IP = 23
+		fallthrough
+	case _f0.IP < 24:
+		_f0.X1 <- 200
+		_f0.IP = 24
+		fallthrough
+	case _f0.IP < 36:
+		switch {
+		case _f0.IP < 25:
+			_f0.X17 = 0
+			_f0.IP = 25
+			fallthrough
+		case _f0.IP < 26:
+			_f0.X18 = _f0.X1
+			_f0.IP = 26
+			fallthrough
+		case _f0.IP < 27:
+			_f0.X19 = *new(int)
+			_f0.IP = 27
+			fallthrough
+		case _f0.IP < 28:
+			_f0.X20 = *new(bool)
+			_f0.IP = 28
+			fallthrough
+		case _f0.IP < 30:
+			select {
+			case _f0.X19, _f0.X20 = <-_f0.X18:
+				_f0.X17 = 1
+			default:
+				_f0.X17 = 2
+			}
+			_f0.IP = 30
+			fallthrough
+		case _f0.IP < 36:
+			switch {
+			case _f0.IP < 31:
+				_f0.X21 = _f0.X17
+				_f0.IP = 31
+				fallthrough
+			case _f0.IP < 36:
+				switch {
+				default:
+					switch {
+					case _f0.IP < 32:
+						_f0.X22 = _f0.X21 == 1
+						_f0.IP = 32
+						fallthrough
+					case _f0.IP < 36:
+						if _f0.X22 {
+							switch {
+							case _f0.IP < 33:
+								_f0.X23 = _f0.X19
+								_f0.IP = 33
+								fallthrough
+							case _f0.IP < 34:
+								_f0.X24 = _f0.X20
+								_f0.IP = 34
+								fallthrough
+							case _f0.IP < 35:
+								if _f0.X24 {
+
+									coroutine.Yield[int, any](_f0.X23)
+								}
+							}
+						} else if _f0.X25 = _f0.X21 == 2; _f0.X25 {
+
+							panic("unreachable")
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func YieldDepth() {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+	} = coroutine.Push[struct {
+		IP int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+		}{}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		yieldDepth()
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		yieldDepth2()
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		yieldDepth()
+	}
+}
+
+//go:noinline
+func yieldDepth() {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 *coroutine.Context[int, any]
+		X1 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 *coroutine.Context[int, any]
+		X1 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 *coroutine.Context[int, any]
+			X1 int
+		}{}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X0 = coroutine.LoadContext[int, any]()
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X1 = _f0.X0.Depth()
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		coroutine.Yield[int, any](_f0.X1)
+	}
+}
+
+//go:noinline
+func yieldDepth2() {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+	} = coroutine.Push[struct {
+		IP int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+		}{}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		yieldDepth()
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		yieldDepth3()
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		yieldDepth()
+	}
+}
+
+//go:noinline
+func yieldDepth3() {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+	} = coroutine.Push[struct {
+		IP int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+		}{}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		yieldDepth()
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		yieldDepth()
+	}
+}
+
+//go:noinline
+func TypeSwitchYieldInCase(_fn0 ...any) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 []any
+		X1 []any
+		X2 int
+		X3 any
+	} = coroutine.Push[struct {
+		IP int
+		X0 []any
+		X1 []any
+		X2 int
+		X3 any
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 []any
+			X1 []any
+			X2 int
+			X3 any
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = _f0.X0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 12:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 12:
+			for ; _f0.X2 < len(_f0.X1); _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					_f0.X3 = _f0.X1[_f0.X2]
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 12:
+					switch v := _f0.X3.(type) {
+					case int:
+						switch {
+						case _f0.IP < 5:
+							coroutine.Yield[int, any](v)
+							_f0.IP = 5
+							fallthrough
+						case _f0.IP < 6:
+
+							coroutine.Yield[int, any](v * 10)
+						}
+					case string:
+						switch {
+						case _f0.IP < 7:
+							coroutine.Yield[int, any](len(v))
+							_f0.IP = 7
+							fallthrough
+						case _f0.IP < 8:
+							coroutine.Yield[int, any](len(v + v))
+						}
+					case nil:
+						coroutine.Yield[int, any](-1)
+					case []int, [2]int:
+						switch {
+						case _f0.IP < 10:
+							coroutine.Yield[int, any](-2)
+							_f0.IP = 10
+							fallthrough
+						case _f0.IP < 11:
+							_ = v
+						}
+					default:
+
+						coroutine.Yield[int, any](-3)
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func Goto(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+		X4 int
+		X5 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+		X4 int
+		X5 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+			X3 int
+			X4 int
+			X5 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+
+loop:
+retry:
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		{
+
+			coroutine.Yield[int, any](_f0.X1)
+		}
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 5:
+		{
+			_f0.X1++
+			if _f0.X1 < _f0.X0 {
+				{
+					_f0.IP = 2
+					goto retry
+				}
+			}
+		}
+		_f0.IP = 5
+		fallthrough
+	case _f0.IP < 10:
+		switch {
+		case _f0.IP < 6:
+			_f0.X2 = 0
+			_f0.IP = 6
+			fallthrough
+		case _f0.IP < 10:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 6 {
+
+			skip:
+				switch {
+				case _f0.IP < 7:
+					if _f0.X2%
+						2 == 0 {
+						{
+							_f0.IP = 9
+							goto skip
+						}
+					}
+					_f0.IP = 7
+					fallthrough
+				case _f0.IP < 9:
+					switch {
+					case _f0.IP < 8:
+						_f0.X3 = _f0.X2 * 10
+						_f0.IP = 8
+						fallthrough
+					case _f0.IP < 9:
+						coroutine.Yield[int, any](_f0.X3)
+					}
+					_f0.IP = 9
+					fallthrough
+				case _f0.IP < 10:
+					{
+
+						coroutine.Yield[int, any](-_f0.X2)
+					}
+				}
+			}
+		}
+		_f0.IP = 10
+		fallthrough
+	case _f0.IP < 11:
+		_f0.X4 = 0
+		_f0.IP = 11
+		fallthrough
+	case _f0.IP < 14:
+		{
+			switch {
+			case _f0.IP < 12:
+				_f0.X5 = 0
+				_f0.IP = 12
+				fallthrough
+			case _f0.IP < 14:
+			_l1:
+				for ; _f0.X5 < 2; _f0.X5, _f0.IP = _f0.X5+1, 12 {
+					switch {
+					case _f0.IP < 13:
+						if _f0.X5 <
+							0 {
+							continue _l1
+						}
+						_f0.IP = 13
+						fallthrough
+					case _f0.IP < 14:
+
+						coroutine.Yield[int, any](100 + _f0.X4*10 + _f0.X5)
+					}
+				}
+			}
+		}
+		_f0.IP = 14
+		fallthrough
+	case _f0.IP < 16:
+		{
+			_f0.X4++
+			if _f0.X4 < 2 {
+				{
+					_f0.IP = 11
+					goto loop
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func SwitchFallthrough(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 17:
+		for ; _f0.X1 < _f0.X0; _f0.X1, _f0.IP = _f0.X1+1, 2 {
+			switch {
+			case _f0.IP < 3:
+				_f0.X2 = 0
+				_f0.IP = 3
+				fallthrough
+			case _f0.IP < 8:
+				switch _f0.X1 {
+				case 0:
+					_f0.X2 = 1
+				case 1:
+					_f0.X2 = 2
+				case 2:
+					_f0.X2 = 3
+				default:
+					_f0.X2 = 4
+				case 4:
+					_f0.X2 = 5
+				}
+				_f0.IP = 8
+				fallthrough
+			case _f0.IP < 17:
+			_l1:
+				switch {
+				default:
+					switch {
+					case _f0.IP < 10:
+						if _f0.X2 == 1 {
+							switch {
+							case _f0.IP < 9:
+								coroutine.Yield[int, any](0)
+								_f0.IP = 9
+								fallthrough
+							case _f0.IP < 10:
+								_f0.X2 = 2
+							}
+						}
+						_f0.IP = 10
+						fallthrough
+					case _f0.IP < 11:
+						if _f0.X2 == 2 {
+
+							coroutine.Yield[int, any](1)
+						}
+						_f0.IP = 11
+						fallthrough
+					case _f0.IP < 13:
+						if _f0.X2 == 3 {
+							switch {
+							case _f0.IP < 12:
+
+								coroutine.Yield[int, any](2)
+								_f0.IP = 12
+								fallthrough
+							case _f0.IP < 13:
+								_f0.X2 = 4
+							}
+						}
+						_f0.IP = 13
+						fallthrough
+					case _f0.IP < 16:
+						if _f0.X2 == 4 {
+							switch {
+							case _f0.IP < 14:
+
+								coroutine.Yield[int, any](3)
+								_f0.IP = 14
+								fallthrough
+							case _f0.IP < 15:
+								if _f0.X1 >
+									4 {
+									break _l1
+								}
+								_f0.IP = 15
+								fallthrough
+							case _f0.IP < 16:
+								_f0.X2 = 5
+							}
+						}
+						_f0.IP = 16
+						fallthrough
+					case _f0.IP < 17:
+						if _f0.X2 == 5 {
+
+							coroutine.Yield[int, any](4)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func LocalTypeDeclarations(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	type _o0 struct{ a, b int }
+	type _o1 []_o0
+	type _o2 = int
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 _o1
+		X2 int
+		X3 int
+		X4 _o1
+		X5 int
+		X6 _o0
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 _o1
+		X2 int
+		X3 int
+		X4 _o1
+		X5 int
+		X6 _o0
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 _o1
+			X2 int
+			X3 int
+			X4 _o1
+			X5 int
+			X6 _o0
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = *new(_o1)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 6:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 6:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					_f0.X1 = append(_f0.X1, _o0{_f0.X2, _f0.X2 * 10})
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 5:
+					_f0.X3 = 100 + _f0.X2
+					_f0.IP = 5
+					fallthrough
+				case _f0.IP < 6:
+					coroutine.Yield[int, any](_f0.X3)
+				}
+			}
+		}
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 10:
+		switch {
+		case _f0.IP < 7:
+			_f0.X4 = _f0.X1
+			_f0.IP = 7
+			fallthrough
+		case _f0.IP < 10:
+			switch {
+			case _f0.IP < 8:
+				_f0.X5 = 0
+				_f0.IP = 8
+				fallthrough
+			case _f0.IP < 10:
+				for ; _f0.X5 < len(_f0.X4); _f0.X5, _f0.IP = _f0.X5+1, 8 {
+					switch {
+					case _f0.IP < 9:
+						_f0.X6 = _f0.X4[_f0.X5]
+						_f0.IP = 9
+						fallthrough
+					case _f0.IP < 10:
+
+						coroutine.Yield[int, any](_f0.X6.a + _f0.X6.b)
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func NestedRangeContinueOuter(_fn0 [][]int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 [][]int
+		X1 [][]int
+		X2 int
+		X3 []int
+		X4 []int
+		X5 int
+		X6 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 [][]int
+		X1 [][]int
+		X2 int
+		X3 []int
+		X4 []int
+		X5 int
+		X6 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 [][]int
+			X1 [][]int
+			X2 int
+			X3 []int
+			X4 []int
+			X5 int
+			X6 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = _f0.X0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 11:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 11:
+		_l0:
+			for ; _f0.X2 < len(_f0.X1); _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					_f0.X3 = _f0.X1[_f0.X2]
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 10:
+					switch {
+					case _f0.IP < 5:
+						_f0.X4 = _f0.X3
+						_f0.IP = 5
+						fallthrough
+					case _f0.IP < 10:
+						switch {
+						case _f0.IP < 6:
+							_f0.X5 = 0
+							_f0.IP = 6
+							fallthrough
+						case _f0.IP < 10:
+							for ; _f0.X5 < len(_f0.X4); _f0.X5, _f0.IP = _f0.X5+1, 6 {
+								switch {
+								case _f0.IP < 7:
+									_f0.X6 = _f0.X4[_f0.X5]
+									_f0.IP = 7
+									fallthrough
+								case _f0.IP < 8:
+
+									coroutine.Yield[int, any](_f0.X6)
+									_f0.IP = 8
+									fallthrough
+								case _f0.IP < 9:
+									if _f0.X6 <
+										0 {
+										continue _l0
+									}
+									_f0.IP = 9
+									fallthrough
+								case _f0.IP < 10:
+									if _f0.X6 ==
+										0 {
+										break _l0
+									}
+								}
+							}
+						}
+					}
+					_f0.IP = 10
+					fallthrough
+				case _f0.IP < 11:
+
+					coroutine.Yield[int, any](100 + _f0.X2)
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func InlineClosureGenerators(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f2 *struct {
+		IP int
+		X0 int
+		X1 func(int, int) func()
+		X2 func()
+		X3 func()
+		X4 func()
+		X5 func()
+		X6 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 func(int, int) func()
+		X2 func()
+		X3 func()
+		X4 func()
+		X5 func()
+		X6 int
+	}](&_c.Stack)
+	if _f2.IP == 0 {
+		*_f2 = struct {
+			IP int
+			X0 int
+			X1 func(int, int) func()
+			X2 func()
+			X3 func()
+			X4 func()
+			X5 func()
+			X6 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f2.IP < 2:
+		_f2.X1 = func(_fn0, _fn1 int) (_ func()) {
+			_c := coroutine.LoadContext[int, any]()
+			var _f1 *struct {
+				IP int
+				X0 int
+				X1 int
+				X2 int
+			} = coroutine.Push[struct {
+				IP int
+				X0 int
+				X1 int
+				X2 int
+			}](&_c.Stack)
+			if _f1.IP == 0 {
+				*_f1 = struct {
+					IP int
+					X0 int
+					X1 int
+					X2 int
+				}{X0: _fn0, X1: _fn1}
+			}
+			defer func() {
+				if !_c.Unwinding() {
+					coroutine.Pop(&_c.Stack)
+				}
+			}()
+			switch {
+			case _f1.IP < 2:
+				_f1.X2 = _f1.X0
+				_f1.IP = 2
+				fallthrough
+			case _f1.IP < 3:
+				coroutine.Yield[int, any](-_f1.X0)
+				_f1.IP = 3
+				fallthrough
+			case _f1.IP < 4:
+				return func() {
+					_c := coroutine.LoadContext[int, any]()
+					var _f0 *struct {
+						IP int
+					} = coroutine.Push[struct {
+						IP int
+					}](&_c.Stack)
+					if _f0.IP == 0 {
+						*_f0 = struct {
+							IP int
+						}{}
+					}
+					defer func() {
+						if !_c.Unwinding() {
+							coroutine.Pop(&_c.Stack)
+						}
+					}()
+					switch {
+					case _f0.IP < 2:
+						coroutine.Yield[int, any](_f1.X2)
+						_f0.IP = 2
+						fallthrough
+					case _f0.IP < 3:
+						_f1.X2 += _f1.X1
+					}
+				}
+			}
+			panic("unreachable")
+		}
+		_f2.IP = 2
+		fallthrough
+	case _f2.IP < 3:
+		_f2.X2 = _f2.X1(0, 2)
+		_f2.IP = 3
+		fallthrough
+	case _f2.IP < 4:
+		_f2.X3 = _f2.X1(1, 2)
+		_f2.IP = 4
+		fallthrough
+	case _f2.IP < 5:
+		_f2.X4, _f2.X5 = _f2.X2, _f2.X3
+		_f2.IP = 5
+		fallthrough
+	case _f2.IP < 8:
+		switch {
+		case _f2.IP < 6:
+			_f2.X6 = 0
+			_f2.IP = 6
+			fallthrough
+		case _f2.IP < 8:
+			for ; _f2.X6 < _f2.X0; _f2.X6, _f2.IP = _f2.X6+1, 6 {
+				switch {
+				case _f2.IP < 7:
+					_f2.X4()
+					_f2.IP = 7
+					fallthrough
+				case _f2.IP < 8:
+					_f2.X5()
+				}
+			}
+		}
+		_f2.IP = 8
+		fallthrough
+	case _f2.IP < 9:
+		_f2.X4()
+	}
+}
+
+type resource struct {
+	open   bool
+	closed int
+}
+
+//go:noinline
+func (_fn0 *resource) close() {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 *resource
+	} = coroutine.Push[struct {
+		IP int
+		X0 *resource
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 *resource
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X0.
+			closed++
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X0.
+			open = false
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		coroutine.Yield[int, any](-_f0.X0.closed)
+	}
+}
+
+//go:noinline
+func ReturnAfterCleanup(_fn0 int) (_ int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 *resource
+		X2 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 *resource
+		X2 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 *resource
+			X2 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = &resource{open: true}
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 6:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 6:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					coroutine.Yield[int, any](_f0.X2)
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 6:
+					if _f0.X2 ==
+						2 {
+						switch {
+						case _f0.IP < 5:
+							_f0.X1.
+								close()
+							_f0.IP = 5
+							fallthrough
+						case _f0.IP < 6:
+							return 100*_f0.X1.closed + _f0.X2
+						}
+					}
+				}
+			}
+		}
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 7:
+		_f0.X1.
+			close()
+		_f0.IP = 7
+		fallthrough
+	case _f0.IP < 8:
+		return 100 * _f0.X1.closed
+	}
+	panic("unreachable")
+}
+
+// commaOkChannel is held in a package variable so it is not stored in the
+// coroutine frames, which do not support serializing channels yet.
+var commaOkChannel chan int
+
+func divmod(a, b int) (int, int) {
+	return a / b, a % b
+}
+
+//go:noinline
+func CommaOkAssignments(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP  int
+		X0  int
+		X1  int
+		X2  int
+		X3  bool
+		X4  map[int]int
+		X5  int
+		X6  bool
+		X7  int
+		X8  bool
+		X9  any
+		X10 int
+		X11 bool
+		X12 string
+		X13 bool
+		X14 int
+		X15 int
+	} = coroutine.Push[struct {
+		IP  int
+		X0  int
+		X1  int
+		X2  int
+		X3  bool
+		X4  map[int]int
+		X5  int
+		X6  bool
+		X7  int
+		X8  bool
+		X9  any
+		X10 int
+		X11 bool
+		X12 string
+		X13 bool
+		X14 int
+		X15 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP  int
+			X0  int
+			X1  int
+			X2  int
+			X3  bool
+			X4  map[int]int
+			X5  int
+			X6  bool
+			X7  int
+			X8  bool
+			X9  any
+			X10 int
+			X11 bool
+			X12 string
+			X13 bool
+			X14 int
+			X15 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		commaOkChannel = make(chan int, _f0.X0)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		for _f0.X1 = 0; _f0.X1 < _f0.X0; _f0.X1++ {
+			commaOkChannel <- _f0.X1 * 10
+		}
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		close(commaOkChannel)
+		_f0.IP = 4
+		fallthrough
+	case _f0.IP < 7:
+	_l0:
+		for ; ; _f0.IP = 4 {
+			switch {
+			case _f0.IP < 5:
+				_f0.X2, _f0.X3 = <-commaOkChannel
+				_f0.IP = 5
+				fallthrough
+			case _f0.IP < 6:
+				coroutine.Yield[int, any](_f0.X2)
+				_f0.IP = 6
+				fallthrough
+			case _f0.IP < 7:
+				if !_f0.X3 {
+					break _l0
+				}
+			}
+		}
+		_f0.IP = 7
+		fallthrough
+	case _f0.IP < 8:
+		_f0.X4 = map[int]int{1: 100}
+		_f0.IP = 8
+		fallthrough
+	case _f0.IP < 9:
+		_f0.X5, _f0.X6 = _f0.X4[1]
+		_f0.IP = 9
+		fallthrough
+	case _f0.IP < 10:
+		_f0.X7, _f0.X8 = _f0.X4[2]
+		_f0.IP = 10
+		fallthrough
+	case _f0.IP < 11:
+		coroutine.Yield[int, any](_f0.X5)
+		_f0.IP = 11
+		fallthrough
+	case _f0.IP < 12:
+		if _f0.X6 &&
+			!_f0.X8 {
+			coroutine.Yield[int, any](_f0.X7)
+		}
+		_f0.IP = 12
+		fallthrough
+	case _f0.IP < 13:
+		_f0.X9 = 42
+		_f0.IP = 13
+		fallthrough
+	case _f0.IP < 14:
+		_f0.X10, _f0.X11 = _f0.X9.(int)
+		_f0.IP = 14
+		fallthrough
+	case _f0.IP < 15:
+		_f0.X12, _f0.X13 = _f0.X9.(string)
+		_f0.IP = 15
+		fallthrough
+	case _f0.IP < 16:
+		coroutine.Yield[int, any](_f0.X10)
+		_f0.IP = 16
+		fallthrough
+	case _f0.IP < 17:
+		if _f0.X11 &&
+			!_f0.X13 {
+			coroutine.Yield[int, any](len(_f0.X12))
+		}
+		_f0.IP = 17
+		fallthrough
+	case _f0.IP < 18:
+		_f0.X14, _f0.X15 = divmod(17, 5)
+		_f0.IP = 18
+		fallthrough
+	case _f0.IP < 19:
+		coroutine.Yield[int, any](_f0.X14)
+		_f0.IP = 19
+		fallthrough
+	case _f0.IP < 20:
+		coroutine.Yield[int, any](_f0.X15)
+		_f0.IP = 20
+		fallthrough
+	case _f0.IP < 21:
+		_f0.X14, _f0.X15 = _f0.X15, _f0.X14
+		_f0.IP = 21
+		fallthrough
+	case _f0.IP < 22:
+		coroutine.Yield[int, any](_f0.X14)
+		_f0.IP = 22
+		fallthrough
+	case _f0.IP < 23:
+		coroutine.Yield[int, any](_f0.X15)
+	}
+}
+
+//go:noinline
+func AssignToFieldsIndexesAndPointers(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP  int
+		X0  int
+		X1  []int
+		X2  [2]point
+		X3  *point
+		X4  map[int]int
+		X5  *map[int]int
+		X6  int
+		X7  int
+		X8  []int
+		X9  int
+		X10 int
+	} = coroutine.Push[struct {
+		IP  int
+		X0  int
+		X1  []int
+		X2  [2]point
+		X3  *point
+		X4  map[int]int
+		X5  *map[int]int
+		X6  int
+		X7  int
+		X8  []int
+		X9  int
+		X10 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP  int
+			X0  int
+			X1  []int
+			X2  [2]point
+			X3  *point
+			X4  map[int]int
+			X5  *map[int]int
+			X6  int
+			X7  int
+			X8  []int
+			X9  int
+			X10 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = make([]int, _f0.X0)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X2 = [2]point{}
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		_f0.X3 = &_f0.X2[1]
+		_f0.IP = 4
+		fallthrough
+	case _f0.IP < 5:
+		_f0.X4 = map[int]int{}
+		_f0.IP = 5
+		fallthrough
+	case _f0.IP < 6:
+		_f0.X5 = &_f0.X4
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 13:
+		switch {
+		case _f0.IP < 7:
+			_f0.X6 = 0
+			_f0.IP = 7
+			fallthrough
+		case _f0.IP < 13:
+			for ; _f0.X6 < _f0.X0; _f0.X6, _f0.IP = _f0.X6+1, 7 {
+				switch {
+				case _f0.IP < 8:
+					_f0.X7 = _f0.X6 * _f0.X6
+					_f0.IP = 8
+					fallthrough
+				case _f0.IP < 9:
+					_f0.X1[_f0.X6] = _f0.X7
+					_f0.IP = 9
+					fallthrough
+				case _f0.IP < 10:
+					_f0.X2[0].X += _f0.X7
+					_f0.IP = 10
+					fallthrough
+				case _f0.IP < 11:
+					_f0.X3.
+						Y = _f0.X6
+					_f0.IP = 11
+					fallthrough
+				case _f0.IP < 12:
+					(*_f0.X5)[_f0.X6%2] += _f0.X7
+					_f0.IP = 12
+					fallthrough
+				case _f0.IP < 13:
+					coroutine.Yield[int, any](_f0.X1[_f0.X6])
+				}
+			}
+		}
+		_f0.IP = 13
+		fallthrough
+	case _f0.IP < 14:
+
+		*_f0.X3 = point{X: _f0.X2[0].X, Y: _f0.X3.Y * 10}
+		_f0.IP = 14
+		fallthrough
+	case _f0.IP < 15:
+		coroutine.Yield[int, any](_f0.X2[0].X)
+		_f0.IP = 15
+		fallthrough
+	case _f0.IP < 16:
+		coroutine.Yield[int, any](_f0.X2[1].X)
+		_f0.IP = 16
+		fallthrough
+	case _f0.IP < 17:
+		coroutine.Yield[int, any](_f0.X2[1].Y)
+		_f0.IP = 17
+		fallthrough
+	case _f0.IP < 18:
+		coroutine.Yield[int, any](_f0.X4[0])
+		_f0.IP = 18
+		fallthrough
+	case _f0.IP < 19:
+		coroutine.Yield[int, any](_f0.X4[1])
+		_f0.IP = 19
+		fallthrough
+	case _f0.IP < 23:
+		switch {
+		case _f0.IP < 20:
+			_f0.X8 = _f0.X1
+			_f0.IP = 20
+			fallthrough
+		case _f0.IP < 23:
+			switch {
+			case _f0.IP < 21:
+				_f0.X9 = 0
+				_f0.IP = 21
+				fallthrough
+			case _f0.IP < 23:
+				for ; _f0.X9 < len(_f0.X8); _f0.X9, _f0.IP = _f0.X9+1, 21 {
+					switch {
+					case _f0.IP < 22:
+						_f0.X10 = _f0.X8[_f0.X9]
+						_f0.IP = 22
+						fallthrough
+					case _f0.IP < 23:
+
+						coroutine.Yield[int, any](_f0.X10)
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func RangeOverMapDeletingKeys(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP  int
+		X0  int
+		X1  map[int]int
+		X2  int
+		X3  int
+		X4  map[int]int
+		X5  []int
+		X6  []int
+		X7  int
+		X8  int
+		X9  int
+		X10 bool
+	} = coroutine.Push[struct {
+		IP  int
+		X0  int
+		X1  map[int]int
+		X2  int
+		X3  int
+		X4  map[int]int
+		X5  []int
+		X6  []int
+		X7  int
+		X8  int
+		X9  int
+		X10 bool
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP  int
+			X0  int
+			X1  map[int]int
+			X2  int
+			X3  int
+			X4  map[int]int
+			X5  []int
+			X6  []int
+			X7  int
+			X8  int
+			X9  int
+			X10 bool
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = make(map[int]int, _f0.X0)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		for _f0.X2 = 0; _f0.X2 < _f0.X0; _f0.X2++ {
+			_f0.X1[_f0.X2] = _f0.X2
+		}
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		_f0.X3 = 0
+		_f0.IP = 4
+		fallthrough
+	case _f0.IP < 15:
+		switch {
+		case _f0.IP < 5:
+			_f0.X4 = _f0.X1
+			_f0.IP = 5
+			fallthrough
+		case _f0.IP < 7:
+			{
+				_f0.X5 = make([]int, 0, len(_f0.X4))
+				for _v2 := range _f0.X4 {
+					_f0.X5 = append(_f0.X5, _v2)
+				}
+			}
+			_f0.IP = 7
+			fallthrough
+		case _f0.IP < 15:
+			switch {
+			case _f0.IP < 8:
+				_f0.X6 = _f0.X5
+				_f0.IP = 8
+				fallthrough
+			case _f0.IP < 15:
+				switch {
+				case _f0.IP < 9:
+					_f0.X7 = 0
+					_f0.IP = 9
+					fallthrough
+				case _f0.IP < 15:
+					for ; _f0.X7 < len(_f0.X6); _f0.X7, _f0.IP = _f0.X7+1, 9 {
+						switch {
+						case _f0.IP < 10:
+							_f0.X8 = _f0.X6[_f0.X7]
+							_f0.IP = 10
+							fallthrough
+						case _f0.IP < 15:
+							switch {
+							case _f0.IP < 11:
+								_f0.X9, _f0.X10 = _f0.X4[_f0.X8]
+								_f0.IP = 11
+								fallthrough
+							case _f0.IP < 15:
+								if _f0.X10 {
+									switch {
+									case _f0.IP < 12:
+
+										delete(_f0.X1, _f0.X8)
+										_f0.IP = 12
+										fallthrough
+									case _f0.IP < 13:
+										delete(_f0.X1, _f0.X9^1)
+										_f0.IP = 13
+										fallthrough
+									case _f0.IP < 14:
+										_f0.X3++
+										_f0.IP = 14
+										fallthrough
+									case _f0.IP < 15:
+										coroutine.Yield[int, any](len(_f0.X1))
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+		_f0.IP = 15
+		fallthrough
+	case _f0.IP < 16:
+
+		coroutine.Yield[int, any](_f0.X3)
+	}
+}
+
+type runes string
+
+//go:noinline
+func RangeOverStrings(_fn0 string) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP  int
+		X0  string
+		X1  string
+		X2  int
+		X3  rune
+		X4  int
+		X5  int
+		X6  string
+		X7  int
+		X8  int
+		X9  runes
+		X10 int
+		X11 int
+		X12 string
+		X13 int
+		X14 rune
+		X15 int
+	} = coroutine.Push[struct {
+		IP  int
+		X0  string
+		X1  string
+		X2  int
+		X3  rune
+		X4  int
+		X5  int
+		X6  string
+		X7  int
+		X8  int
+		X9  runes
+		X10 int
+		X11 int
+		X12 string
+		X13 int
+		X14 rune
+		X15 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP  int
+			X0  string
+			X1  string
+			X2  int
+			X3  rune
+			X4  int
+			X5  int
+			X6  string
+			X7  int
+			X8  int
+			X9  runes
+			X10 int
+			X11 int
+			X12 string
+			X13 int
+			X14 rune
+			X15 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 6:
+		switch {
+		case _f0.IP < 2:
+			_f0.X1 = _f0.X0
+			_f0.IP = 2
+			fallthrough
+		case _f0.IP < 6:
+			switch {
+			case _f0.IP < 3:
+				_f0.X2 = 0
+				_f0.IP = 3
+				fallthrough
+			case _f0.IP < 6:
+				for ; _f0.X2 < len(_f0.X1); _f0.X2, _f0.IP = _f0.X2+_f0.X4, 3 {
+					switch {
+					case _f0.IP < 4:
+						_f0.X3, _f0.X4 = utf8.DecodeRuneInString(_f0.X1[_f0.X2:])
+						_f0.IP = 4
+						fallthrough
+					case _f0.IP < 5:
+
+						coroutine.Yield[int, any](_f0.X2)
+						_f0.IP = 5
+						fallthrough
+					case _f0.IP < 6:
+						coroutine.Yield[int, any](int(_f0.X3))
+					}
+				}
+			}
+		}
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 7:
+		_f0.X5 = 0
+		_f0.IP = 7
+		fallthrough
+	case _f0.IP < 12:
+		switch {
+		case _f0.IP < 8:
+			_f0.X6 = _f0.X0
+			_f0.IP = 8
+			fallthrough
+		case _f0.IP < 12:
+			switch {
+			case _f0.IP < 9:
+				_f0.X7 = 0
+				_f0.IP = 9
+				fallthrough
+			case _f0.IP < 12:
+				for ; _f0.X7 < len(_f0.X6); _f0.X7, _f0.IP = _f0.X7+_f0.X8, 9 {
+					switch {
+					case _f0.IP < 10:
+						_, _f0.X8 = utf8.DecodeRuneInString(_f0.X6[_f0.X7:])
+						_f0.IP = 10
+						fallthrough
+					case _f0.IP < 11:
+						_f0.X5++
+						_f0.IP = 11
+						fallthrough
+					case _f0.IP < 12:
+						coroutine.Yield[int, any](_f0.X5)
+					}
+				}
+			}
+		}
+		_f0.IP = 12
+		fallthrough
+	case _f0.IP < 16:
+		switch {
+		case _f0.IP < 13:
+			_f0.X9 = runes(_f0.X0)
+			_f0.IP = 13
+			fallthrough
+		case _f0.IP < 16:
+			switch {
+			case _f0.IP < 14:
+				_f0.X10 = 0
+				_f0.IP = 14
+				fallthrough
+			case _f0.IP < 16:
+				for ; _f0.X10 < len(_f0.X9); _f0.X10, _f0.IP = _f0.X10+_f0.X11, 14 {
+					switch {
+					case _f0.IP < 15:
+						_, _f0.X11 = utf8.DecodeRuneInString(string(_f0.X9[_f0.X10:]))
+						_f0.IP = 15
+						fallthrough
+					case _f0.IP < 16:
+						coroutine.Yield[int, any](_f0.X10)
+					}
+				}
+			}
+		}
+		_f0.IP = 16
+		fallthrough
+	case _f0.IP < 20:
+		switch {
+		case _f0.IP < 17:
+			_f0.X12 = "ü!"
+			_f0.IP = 17
+			fallthrough
+		case _f0.IP < 20:
+			switch {
+			case _f0.IP < 18:
+				_f0.X13 = 0
+				_f0.IP = 18
+				fallthrough
+			case _f0.IP < 20:
+				for ; _f0.X13 < len(_f0.X12); _f0.X13, _f0.IP = _f0.X13+_f0.X15, 18 {
+					switch {
+					case _f0.IP < 19:
+						_f0.X14, _f0.X15 = utf8.DecodeRuneInString(_f0.X12[_f0.X13:])
+						_f0.IP = 19
+						fallthrough
+					case _f0.IP < 20:
+						coroutine.Yield[int, any](int(_f0.X14))
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func ClosureCapturingLoopIndex(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 []func()
+		X2 int
+		X3 []func()
+		X4 int
+		X5 func()
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 []func()
+		X2 int
+		X3 []func()
+		X4 int
+		X5 func()
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 []func()
+			X2 int
+			X3 []func()
+			X4 int
+			X5 func()
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = *new([]func())
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 5:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 5:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					_f0.X1 = append(_f0.X1, func() { coroutine.Yield[int, any](_f0.X2) })
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 5:
+					_f0.X1[_f0.X2]()
+				}
+			}
+		}
+		_f0.IP = 5
+		fallthrough
+	case _f0.IP < 9:
+		switch {
+		case _f0.IP < 6:
+			_f0.X3 = _f0.X1
+			_f0.IP = 6
+			fallthrough
+		case _f0.IP < 9:
+			switch {
+			case _f0.IP < 7:
+				_f0.X4 = 0
+				_f0.IP = 7
+				fallthrough
+			case _f0.IP < 9:
+				for ; _f0.X4 < len(_f0.X3); _f0.X4, _f0.IP = _f0.X4+1, 7 {
+					switch {
+					case _f0.IP < 8:
+						_f0.X5 = _f0.X3[_f0.X4]
+						_f0.IP = 8
+						fallthrough
+					case _f0.IP < 9:
+						_f0.X5()
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func RangeOverChannel(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 chan int
+		X2 chan int
+		X3 int
+		X4 bool
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 chan int
+		X2 chan int
+		X3 int
+		X4 bool
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 chan int
+			X2 chan int
+			X3 int
+			X4 bool
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = make(chan int, 1)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X1 <- 0
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 9:
+		switch {
+		case _f0.IP < 4:
+			_f0.X2 = _f0.X1
+			_f0.IP = 4
+			fallthrough
+		case _f0.IP < 9:
+		_l0:
+			for ; ; _f0.IP = 4 {
+				switch {
+				case _f0.IP < 5:
+					_f0.X3, _f0.X4 = <-_f0.X2
+					_f0.IP = 5
+					fallthrough
+				case _f0.IP < 6:
+					if !_f0.X4 {
+						break _l0
+					}
+					_f0.IP = 6
+					fallthrough
+				case _f0.IP < 7:
+
+					coroutine.Yield[int, any](_f0.X3)
+					_f0.IP = 7
+					fallthrough
+				case _f0.IP < 9:
+					if _f0.X3 < _f0.X0 {
+						_f0.X1 <- _f0.X3 + 1
+					} else {
+						close(_f0.X1)
+					}
+				}
+			}
+		}
+		_f0.IP = 9
+		fallthrough
+	case _f0.IP < 10:
+
+		coroutine.Yield[int, any](-1)
+	}
+}
+
+//go:noinline
+func InlineVarAndConstDeclarations(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	const _o0 = 10
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 []byte
+		X2 int
+		X3 int
+		X4 int
+		X5 string
+		X6 []byte
+		X7 int
+		X8 byte
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 []byte
+		X2 int
+		X3 int
+		X4 int
+		X5 string
+		X6 []byte
+		X7 int
+		X8 byte
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 []byte
+			X2 int
+			X3 int
+			X4 int
+			X5 string
+			X6 []byte
+			X7 int
+			X8 byte
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = *new([]byte)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 10:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 10:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					_f0.X3 = *new(int)
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 6:
+					switch {
+					case _f0.IP < 5:
+						_f0.X4 = _f0.X2 * _o0
+						_f0.IP = 5
+						fallthrough
+					case _f0.IP < 6:
+						_f0.X5 = *new(string)
+					}
+					_f0.IP = 6
+					fallthrough
+				case _f0.IP < 7:
+					_f0.X3 += _f0.X4
+					_f0.IP = 7
+					fallthrough
+				case _f0.IP < 8:
+					_f0.X5 += "x"
+					_f0.IP = 8
+					fallthrough
+				case _f0.IP < 9:
+					_f0.X1 = append(_f0.X1, byte(_f0.X3))
+					_f0.IP = 9
+					fallthrough
+				case _f0.IP < 10:
+					coroutine.Yield[int, any](_f0.X3 + len(_f0.X5))
+				}
+			}
+		}
+		_f0.IP = 10
+		fallthrough
+	case _f0.IP < 11:
+
+		coroutine.Yield[int, any](len(_f0.X1))
+		_f0.IP = 11
+		fallthrough
+	case _f0.IP < 15:
+		switch {
+		case _f0.IP < 12:
+			_f0.X6 = _f0.X1
+			_f0.IP = 12
+			fallthrough
+		case _f0.IP < 15:
+			switch {
+			case _f0.IP < 13:
+				_f0.X7 = 0
+				_f0.IP = 13
+				fallthrough
+			case _f0.IP < 15:
+				for ; _f0.X7 < len(_f0.X6); _f0.X7, _f0.IP = _f0.X7+1, 13 {
+					switch {
+					case _f0.IP < 14:
+						_f0.X8 = _f0.X6[_f0.X7]
+						_f0.IP = 14
+						fallthrough
+					case _f0.IP < 15:
+
+						coroutine.Yield[int, any](int(_f0.X8))
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func StructSendGenerator(_fn0 int) {
+	_c := coroutine.LoadContext[int, struct {
+	}]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		for ; _f0.X1 < _f0.X0; _f0.X1, _f0.IP = _f0.X1+1, 2 {
+			coroutine.Yield[int, struct{}](_f0.X1 * _f0.X1)
+		}
+	}
+}
+
+//go:noinline
+func YieldInExpression(_fn0 int) {
+	_c := coroutine.LoadContext[int, int]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+		X4 int
+		X5 int
+		X6 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+		X4 int
+		X5 int
+		X6 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+			X3 int
+			X4 int
+			X5 int
+			X6 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = coroutine.Yield[int, int](_f0.X0)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X2 = _f0.X1 + 1
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		_f0.X3 = coroutine.Yield[int, int](_f0.X2)
+		_f0.IP = 4
+		fallthrough
+	case _f0.IP < 5:
+		_f0.X4 = double(_f0.X3)
+		_f0.IP = 5
+		fallthrough
+	case _f0.IP < 6:
+		_f0.X5 = coroutine.Yield[int, int](_f0.X2 + 1)
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 7:
+		_f0.X6 = _f0.X4 * _f0.X5
+		_f0.IP = 7
+		fallthrough
+	case _f0.IP < 8:
+		coroutine.Yield[int, int](_f0.X6)
+	}
+}
+
+//go:noinline
+func RunningTotal(_fn0 int) {
+	_c := coroutine.LoadContext[int, int]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+			X3 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 5:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 5:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					_f0.X3 = coroutine.Yield[int, int](_f0.X1)
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 5:
+					_f0.X1 += _f0.X3
+				}
+			}
+		}
+		_f0.IP = 5
+		fallthrough
+	case _f0.IP < 6:
+
+		coroutine.Yield[int, int](_f0.X1)
+	}
+}
+
+//go:noinline
+func SumSentValues(_fn0 int) (_ int) {
+	_c := coroutine.LoadContext[int, int]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+			X3 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 5:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 5:
+			for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+				switch {
+				case _f0.IP < 4:
+					_f0.X3 = coroutine.Yield[int, int](_f0.X2)
+					_f0.IP = 4
+					fallthrough
+				case _f0.IP < 5:
+					_f0.X1 += _f0.X3
+				}
+			}
+		}
+		_f0.IP = 5
+		fallthrough
+	case _f0.IP < 6:
+
+		return _f0.X1
+	}
+	panic("unreachable")
+}
+
+// CancelCleanups counts the runs of the function deferred by
+// CountUntilCancelled, which only returns by being cancelled.
+var CancelCleanups int
+
+//go:noinline
+func CountUntilCancelled() {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 []func()
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 []func()
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 []func()
+		}{}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			defer coroutine.Pop(&_c.Stack)
+			_c.RunDefers(_f0.X1, recover())
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = append(_f0.X1, func() { CancelCleanups++ })
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 4:
+		switch {
+		case _f0.IP < 3:
+			_f0.X0 = 0
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 4:
+			for ; ; _f0.X0, _f0.IP = _f0.X0+1, 3 {
+				coroutine.Yield[int, any](_f0.X0)
+			}
+		}
+	}
+}
+
+//go:noinline
+func CancelSelfAndReturn(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 *coroutine.Context[int, any]
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 *coroutine.Context[int, any]
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 *coroutine.Context[int, any]
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+
+		coroutine.Yield[int, any](_f0.X0)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X1 = coroutine.LoadContext[int, any]()
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		_f0.X1.Cancel()
+	}
+}
+
+//go:noinline
+func RecursiveYield(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+
+		if _f0.X0 == 0 {
+			return
+		}
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		coroutine.Yield[int, any](_f0.X0)
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		RecursiveYield(_f0.X0 - 1)
+		_f0.IP = 4
+		fallthrough
+	case _f0.IP < 5:
+		coroutine.Yield[int, any](-_f0.X0)
+	}
+}
+
+//go:noinline
+func MutuallyRecursiveYield(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	if _f0.X0 >
+		0 {
+		switch {
+		case _f0.IP < 2:
+			coroutine.Yield[int, any](_f0.X0)
+			_f0.IP = 2
+			fallthrough
+		case _f0.IP < 3:
+			mutuallyRecursiveYield(_f0.X0 - 1)
+		}
+	}
+}
+
+//go:noinline
+func mutuallyRecursiveYield(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	if _f0.X0 >
+		0 {
+		switch {
+		case _f0.IP < 2:
+			coroutine.Yield[int, any](_f0.X0 * 10)
+			_f0.IP = 2
+			fallthrough
+		case _f0.IP < 3:
+			MutuallyRecursiveYield(_f0.X0 - 1)
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 4:
+			coroutine.Yield[int, any](_f0.X0 * 100)
+		}
+	}
+}
+
+//go:noinline
+func SortWithClosure(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 []int
+		X2 []int
+		X3 int
+		X4 []int
+		X5 int
+		X6 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 []int
+		X2 []int
+		X3 int
+		X4 []int
+		X5 int
+		X6 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 []int
+			X2 []int
+			X3 int
+			X4 []int
+			X5 int
+			X6 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = make([]int, _f0.X0)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 6:
+		switch {
+		case _f0.IP < 3:
+			_f0.X2 = _f0.X1
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 6:
+			switch {
+			case _f0.IP < 4:
+				_f0.X3 = 0
+				_f0.IP = 4
+				fallthrough
+			case _f0.IP < 6:
+				for ; _f0.X3 < len(_f0.X2); _f0.X3, _f0.IP = _f0.X3+1, 4 {
+					switch {
+					case _f0.IP < 5:
+						_f0.X1[_f0.X3] = _f0.X0 - _f0.X3
+						_f0.IP = 5
+						fallthrough
+					case _f0.IP < 6:
+						coroutine.Yield[int, any](_f0.X1[_f0.X3])
+					}
+				}
+			}
+		}
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 7:
+
+		sort.Slice(_f0.X1, func(i, j int) bool { return _f0.X1[i] < _f0.X1[j] })
+		_f0.IP = 7
+		fallthrough
+	case _f0.IP < 11:
+		switch {
+		case _f0.IP < 8:
+			_f0.X4 = _f0.X1
+			_f0.IP = 8
+			fallthrough
+		case _f0.IP < 11:
+			switch {
+			case _f0.IP < 9:
+				_f0.X5 = 0
+				_f0.IP = 9
+				fallthrough
+			case _f0.IP < 11:
+				for ; _f0.X5 < len(_f0.X4); _f0.X5, _f0.IP = _f0.X5+1, 9 {
+					switch {
+					case _f0.IP < 10:
+						_f0.X6 = _f0.X4[_f0.X5]
+						_f0.IP = 10
+						fallthrough
+					case _f0.IP < 11:
+
+						coroutine.Yield[int, any](_f0.X6 * 10)
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func yieldAndReturn(_fn0 int) (_ int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		coroutine.Yield[int, any](_f0.X0)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		return _f0.X0
+	}
+	panic("unreachable")
+}
+
+//go:noinline
+func incrementAndYield(_fn0 *int) (_ int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 *int
+	} = coroutine.Push[struct {
+		IP int
+		X0 *int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 *int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		*_f0.X0++
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		coroutine.Yield[int, any](*_f0.X0)
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		return *_f0.X0
+	}
+	panic("unreachable")
+}
+
+func double(v int) int { return 2 * v }
+
+//go:noinline
+func MultipleCallsInExpression(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP  int
+		X0  int
+		X1  int
+		X2  int
+		X3  int
+		X4  int
+		X5  int
+		X6  int
+		X7  int
+		X8  int
+		X9  int
+		X10 int
+		X11 int
+	} = coroutine.Push[struct {
+		IP  int
+		X0  int
+		X1  int
+		X2  int
+		X3  int
+		X4  int
+		X5  int
+		X6  int
+		X7  int
+		X8  int
+		X9  int
+		X10 int
+		X11 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP  int
+			X0  int
+			X1  int
+			X2  int
+			X3  int
+			X4  int
+			X5  int
+			X6  int
+			X7  int
+			X8  int
+			X9  int
+			X10 int
+			X11 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = yieldAndReturn(1)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X2 = yieldAndReturn(2)
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		_f0.X3 = _f0.X1 + _f0.X2
+		_f0.IP = 4
+		fallthrough
+	case _f0.IP < 5:
+		coroutine.Yield[int, any](_f0.X3)
+		_f0.IP = 5
+		fallthrough
+	case _f0.IP < 6:
+		_f0.X4 = double(_f0.X0)
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 7:
+		_f0.X5 = incrementAndYield(&_f0.X0)
+		_f0.IP = 7
+		fallthrough
+	case _f0.IP < 8:
+		_f0.X6 = _f0.X4 + _f0.X5
+		_f0.IP = 8
+		fallthrough
+	case _f0.IP < 9:
+		coroutine.Yield[int, any](_f0.X6)
+		_f0.IP = 9
+		fallthrough
+	case _f0.IP < 10:
+		_f0.X7 = yieldAndReturn(_f0.X0)
+		_f0.IP = 10
+		fallthrough
+	case _f0.IP < 11:
+		_f0.X8 = double(_f0.X7)
+		_f0.IP = 11
+		fallthrough
+	case _f0.IP < 12:
+		_f0.X9 = double(_f0.X0)
+		_f0.IP = 12
+		fallthrough
+	case _f0.IP < 13:
+		_f0.X10 = yieldAndReturn(_f0.X9)
+		_f0.IP = 13
+		fallthrough
+	case _f0.IP < 14:
+		_f0.X11 = _f0.X8 * _f0.X10
+		_f0.IP = 14
+		fallthrough
+	case _f0.IP < 15:
+		coroutine.Yield[int, any](_f0.X11)
+	}
+}
+
+//go:noinline
+func SwitchWithInitStatement(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 bool
+		X4 bool
+		X5 int
+		X6 bool
+		X7 bool
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 bool
+		X4 bool
+		X5 int
+		X6 bool
+		X7 bool
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+			X3 bool
+			X4 bool
+			X5 int
+			X6 bool
+			X7 bool
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 7:
+		switch {
+		case _f0.IP < 2:
+			_f0.X1 = yieldAndReturn(_f0.X0)
+			_f0.IP = 2
+			fallthrough
+		case _f0.IP < 3:
+			_f0.X2 = incrementAndYield(&_f0.X1)
+			_f0.IP = 3
+			fallthrough
+		case _f0.IP < 7:
+			switch {
+			default:
+				if _f0.X3 = _f0.X2 == _f0.X0; _f0.X3 {
+
+					panic("unreachable")
+				} else if _f0.X4 = _f0.X2 == _f0.X0+
+					1; _f0.X4 {
+					switch {
+					case _f0.IP < 5:
+						coroutine.Yield[int, any](_f0.X1)
+						_f0.IP = 5
+						fallthrough
+					case _f0.IP < 6:
+						coroutine.Yield[int, any](_f0.X1 * 10)
+					}
+				} else {
+
+					panic("unreachable")
+				}
+			}
+		}
+		_f0.IP = 7
+		fallthrough
+	case _f0.IP < 11:
+		switch {
+		case _f0.IP < 8:
+			_f0.X5 = yieldAndReturn(-_f0.X0)
+			_f0.IP = 8
+			fallthrough
+		case _f0.IP < 11:
+			switch {
+			default:
+				if _f0.X6 = _f0.X5 >
+					0; _f0.X6 {
+					panic("unreachable")
+				} else if _f0.X7 = _f0.X5 <
+					0; _f0.X7 {
+					switch {
+					case _f0.IP < 10:
+						coroutine.Yield[int, any](_f0.X5 * 10)
+						_f0.IP = 10
+						fallthrough
+					case _f0.IP < 11:
+						coroutine.Yield[int, any](_f0.X5)
+					}
+				}
+			}
+		}
+	}
+}
+
+//go:noinline
+func ForLoopWithMultiplePostAssignments(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1, _f0.X2 = 0, _f0.X0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		for ; _f0.X1 < _f0.X2; _f0.X1, _f0.X2, _f0.IP = _f0.X1+1, _f0.X2-1, 2 {
+			coroutine.Yield[int, any](_f0.X1*10 + _f0.X2)
+		}
+	}
+}
+
+//go:noinline
+func ForLoopWithMultipleInitVariables(_fn0 []int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 []int
+		X1 int
+		X2 int
+		X3 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 []int
+		X1 int
+		X2 int
+		X3 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 []int
+			X1 int
+			X2 int
+			X3 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = yieldAndReturn(0)
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		_f0.X2, _f0.X3 = _f0.X1, len(_f0.X0)-1
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 5:
+		for ; _f0.X2 < _f0.X3; _f0.X2, _f0.X3, _f0.IP = _f0.X2+1, _f0.X3-1, 3 {
+			switch {
+			case _f0.IP < 4:
+				coroutine.Yield[int, any](_f0.X0[_f0.X2])
+				_f0.IP = 4
+				fallthrough
+			case _f0.IP < 5:
+				coroutine.Yield[int, any](_f0.X0[_f0.X3])
+			}
+		}
+	}
+}
+
+//go:noinline
+func ForLoopWithYieldingPostStatement(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+			X3 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 7:
+		for ; _f0.X1 < _f0.X0; _f0.IP = 2 {
+			switch {
+			case _f0.IP < 4:
+			_l1:
+				switch {
+				default:
+					switch {
+					case _f0.IP < 3:
+						if _f0.X1%
+							2 == 1 {
+							break _l1
+						}
+						_f0.IP = 3
+						fallthrough
+					case _f0.IP < 4:
+
+						coroutine.Yield[int, any](_f0.X1)
+					}
+				}
+				_f0.IP = 4
+				fallthrough
+			case _f0.IP < 5:
+				_f0.X2 = yieldAndReturn(_f0.X1 * 10)
+				_f0.IP = 5
+				fallthrough
+			case _f0.IP < 6:
+				_f0.X3 = _f0.X2 / 10
+				_f0.IP = 6
+				fallthrough
+			case _f0.IP < 7:
+				_f0.X1 = _f0.X3 + 1
+			}
+		}
+	}
+}
+
+//go:noinline
+func ForLoopWithPostStatementAndLabeledContinue(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+		X3 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+			X3 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 7:
+		for ; _f0.X1 < _f0.X0; _f0.IP = 2 {
+			switch {
+			case _f0.IP < 5:
+			_l1:
+				switch {
+				default:
+					switch {
+					case _f0.IP < 3:
+						_f0.X2 = 0
+						_f0.IP = 3
+						fallthrough
+					case _f0.IP < 5:
+						for ; _f0.X2 < _f0.X0; _f0.X2, _f0.IP = _f0.X2+1, 3 {
+							switch {
+							case _f0.IP < 4:
+								if _f0.X2 > _f0.X1 {
+									break _l1
+								}
+								_f0.IP = 4
+								fallthrough
+							case _f0.IP < 5:
+
+								coroutine.Yield[int, any](_f0.X1*10 + _f0.X2)
+							}
+						}
+					}
+				}
+				_f0.IP = 5
+				fallthrough
+			case _f0.IP < 6:
+				_f0.X3 = double(_f0.X1)
+				_f0.IP = 6
+				fallthrough
+			case _f0.IP < 7:
+				_f0.X1 = _f0.X3 + 1
+			}
+		}
+	}
+}
+
+//go:noinline
+func ShortCircuitEvaluation(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP  int
+		X0  int
+		X1  bool
+		X2  int
+		X3  bool
+		X4  bool
+		X5  int
+		X6  bool
+		X7  bool
+		X8  int
+		X9  bool
+		X10 bool
+		X11 int
+		X12 bool
+		X13 int
+		X14 bool
+		X15 bool
+		X16 int
+		X17 bool
+		X18 bool
+		X19 int
+		X20 bool
+	} = coroutine.Push[struct {
+		IP  int
+		X0  int
+		X1  bool
+		X2  int
+		X3  bool
+		X4  bool
+		X5  int
+		X6  bool
+		X7  bool
+		X8  int
+		X9  bool
+		X10 bool
+		X11 int
+		X12 bool
+		X13 int
+		X14 bool
+		X15 bool
+		X16 int
+		X17 bool
+		X18 bool
+		X19 int
+		X20 bool
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP  int
+			X0  int
+			X1  bool
+			X2  int
+			X3  bool
+			X4  bool
+			X5  int
+			X6  bool
+			X7  bool
+			X8  int
+			X9  bool
+			X10 bool
+			X11 int
+			X12 bool
+			X13 int
+			X14 bool
+			X15 bool
+			X16 int
+			X17 bool
+			X18 bool
+			X19 int
+			X20 bool
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 6:
+		switch {
+		case _f0.IP < 2:
+			_f0.X1 = _f0.X0 >
+				0
+			_f0.IP = 2
+			fallthrough
+		case _f0.IP < 4:
+			if _f0.X1 {
+				switch {
+				case _f0.IP < 3:
+					_f0.X2 = yieldAndReturn(_f0.X0)
+					_f0.IP = 3
+					fallthrough
+				case _f0.IP < 4:
+					_f0.X1 = _f0.X2 == _f0.X0
+				}
+			}
+			_f0.IP = 4
+			fallthrough
+		case _f0.IP < 5:
+			_f0.X3 = _f0.X1
+			_f0.IP = 5
+			fallthrough
+		case _f0.IP < 6:
+			if _f0.X3 {
+				coroutine.Yield[int, any](1)
+			}
+		}
+		_f0.IP = 6
+		fallthrough
+	case _f0.IP < 11:
+		switch {
+		case _f0.IP < 7:
+			_f0.X4 = _f0.X0 <
+				0
+			_f0.IP = 7
+			fallthrough
+		case _f0.IP < 9:
+			if _f0.X4 {
+				switch {
+				case _f0.IP < 8:
+					_f0.X5 = yieldAndReturn(-1)
+					_f0.IP = 8
+					fallthrough
+				case _f0.IP < 9:
+					_f0.X4 = _f0.X5 == -1
+				}
+			}
+			_f0.IP = 9
+			fallthrough
+		case _f0.IP < 10:
+			_f0.X6 = _f0.X4
+			_f0.IP = 10
+			fallthrough
+		case _f0.IP < 11:
+			if _f0.X6 {
+				panic("unreachable")
+			}
+		}
+		_f0.IP = 11
+		fallthrough
+	case _f0.IP < 16:
+		switch {
+		case _f0.IP < 12:
+			_f0.X7 = _f0.X0 <
+				0
+			_f0.IP = 12
+			fallthrough
+		case _f0.IP < 14:
+			if !_f0.X7 {
+				switch {
+				case _f0.IP < 13:
+					_f0.X8 = yieldAndReturn(_f0.X0 * 10)
+					_f0.IP = 13
+					fallthrough
+				case _f0.IP < 14:
+					_f0.X7 = _f0.X8 == _f0.X0*10
+				}
+			}
+			_f0.IP = 14
+			fallthrough
+		case _f0.IP < 15:
+			_f0.X9 = _f0.X7
+			_f0.IP = 15
+			fallthrough
+		case _f0.IP < 16:
+			if _f0.X9 {
+				coroutine.Yield[int, any](2)
+			}
+		}
+		_f0.IP = 16
+		fallthrough
+	case _f0.IP < 21:
+		switch {
+		case _f0.IP < 17:
+			_f0.X10 = _f0.X0 >
+				0
+			_f0.IP = 17
+			fallthrough
+		case _f0.IP < 19:
+			if !_f0.X10 {
+				switch {
+				case _f0.IP < 18:
+					_f0.X11 = yieldAndReturn(-2)
+					_f0.IP = 18
+					fallthrough
+				case _f0.IP < 19:
+					_f0.X10 = _f0.X11 == -2
+				}
+			}
+			_f0.IP = 19
+			fallthrough
+		case _f0.IP < 20:
+			_f0.X12 = _f0.X10
+			_f0.IP = 20
+			fallthrough
+		case _f0.IP < 21:
+			if _f0.X12 {
+				coroutine.Yield[int, any](3)
+			}
+		}
+		_f0.IP = 21
+		fallthrough
+	case _f0.IP < 22:
+		_f0.X13 = yieldAndReturn(_f0.X0)
+		_f0.IP = 22
+		fallthrough
+	case _f0.IP < 23:
+		_f0.X14 = _f0.X13 > 0
+		_f0.IP = 23
+		fallthrough
+	case _f0.IP < 24:
+		_f0.X15 = _f0.X14
+		_f0.IP = 24
+		fallthrough
+	case _f0.IP < 26:
+		if _f0.X15 {
+			switch {
+			case _f0.IP < 25:
+				_f0.X16 = yieldAndReturn(_f0.X0 * 100)
+				_f0.IP = 25
+				fallthrough
+			case _f0.IP < 26:
+				_f0.X15 = _f0.X16 > 0
+			}
+		}
+		_f0.IP = 26
+		fallthrough
+	case _f0.IP < 27:
+		_f0.X17 = _f0.X15 && _f0.X0 < 0
+		_f0.IP = 27
+		fallthrough
+	case _f0.IP < 28:
+		_f0.X18 = _f0.X17
+		_f0.IP = 28
+		fallthrough
+	case _f0.IP < 30:
+		if !_f0.X18 {
+			switch {
+			case _f0.IP < 29:
+				_f0.X19 = yieldAndReturn(4)
+				_f0.IP = 29
+				fallthrough
+			case _f0.IP < 30:
+				_f0.X18 = _f0.X19 > 0
+			}
+		}
+		_f0.IP = 30
+		fallthrough
+	case _f0.IP < 31:
+		_f0.X20 = _f0.X18
+		_f0.IP = 31
+		fallthrough
+	case _f0.IP < 32:
+		if _f0.X20 {
+
+			coroutine.Yield[int, any](5)
+		}
+	}
+}
+
+//go:noinline
+func recoverPanic(_fn0 int) (_fn1 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 []func()
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 []func()
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 []func()
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			defer coroutine.Pop(&_c.Stack)
+			if _c.RunDefers(_f0.X2, recover()) {
+				_fn1 = _f0.X1
+			}
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X2 = append(_f0.X2, func() {
+			if r := coroutine.LoadContext[int, any]().Recover(); r != nil {
+				_f0.X1 = r.(int)
+			}
+		})
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		coroutine.Yield[int, any](_f0.X0)
+		_f0.IP = 3
+		fallthrough
+	case _f0.IP < 4:
+		panic(_f0.X0 * 10)
+	}
+	panic("unreachable")
+}
+
+//go:noinline
+func RecoverAfterYield(_fn0 int) {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	} = coroutine.Push[struct {
+		IP int
+		X0 int
+		X1 int
+		X2 int
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+		}{X0: _fn0}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			coroutine.Pop(&_c.Stack)
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X1 = 0
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 4:
+		for ; _f0.X1 < _f0.X0; _f0.X1, _f0.IP = _f0.X1+1, 2 {
+			switch {
+			case _f0.IP < 3:
+				_f0.X2 = recoverPanic(_f0.X1)
+				_f0.IP = 3
+				fallthrough
+			case _f0.IP < 4:
+				coroutine.Yield[int, any](_f0.X2)
+			}
+		}
+	}
+}
+
+//go:noinline
+func YieldWhilePanicking() {
+	_c := coroutine.LoadContext[int, any]()
+	var _f0 *struct {
+		IP int
+		X0 []func()
+	} = coroutine.Push[struct {
+		IP int
+		X0 []func()
+	}](&_c.Stack)
+	if _f0.IP == 0 {
+		*_f0 = struct {
+			IP int
+			X0 []func()
+		}{}
+	}
+	defer func() {
+		if !_c.Unwinding() {
+			defer coroutine.Pop(&_c.Stack)
+			_c.RunDefers(_f0.X0, recover())
+		}
+	}()
+	switch {
+	case _f0.IP < 2:
+		_f0.X0 = append(_f0.X0, func() {
+			coroutine.Yield[int, any](1)
+		})
+		_f0.IP = 2
+		fallthrough
+	case _f0.IP < 3:
+		panic("boom")
+	}
+}
+func init() {
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.AssignToFieldsIndexesAndPointers")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.CancelSelfAndReturn")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.ClosureCapturingLoopIndex")
+	_types.RegisterClosure[func(), struct {
+		F  uintptr
+		X0 *struct {
+			IP int
+			X0 int
+			X1 []func()
+			X2 int
+			X3 []func()
+			X4 int
+			X5 func()
+		}
+	}]("github.com/stealthrocket/coroutine/compiler/testdata.ClosureCapturingLoopIndex.func2")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.CommaOkAssignments")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.CountUntilCancelled")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.CountUntilCancelled.func2")
+	_types.RegisterFunc[func(n int)]("github.com/stealthrocket/coroutine/compiler/testdata.Double")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.EvenSquareGenerator")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.FizzBuzzIfGenerator")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.FizzBuzzSwitchGenerator")
+	_types.RegisterFunc[func(_fn0 []int)]("github.com/stealthrocket/coroutine/compiler/testdata.ForLoopWithMultipleInitVariables")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.ForLoopWithMultiplePostAssignments")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.ForLoopWithPostStatementAndLabeledContinue")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.ForLoopWithYieldingPostStatement")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.Goto")
+	_types.RegisterFunc[func(n int)]("github.com/stealthrocket/coroutine/compiler/testdata.Identity")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.InlineClosureGenerators")
+	_types.RegisterFunc[func(_fn0, _fn1 int) (_ func())]("github.com/stealthrocket/coroutine/compiler/testdata.InlineClosureGenerators.func2")
+	_types.RegisterClosure[func(), struct {
+		F  uintptr
+		X0 *struct {
+			IP int
+			X0 int
+			X1 int
+			X2 int
+		}
+	}]("github.com/stealthrocket/coroutine/compiler/testdata.InlineClosureGenerators.func2.2")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.InlineVarAndConstDeclarations")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.LocalTypeDeclarations")
+	_types.RegisterFunc[func(_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.LoopBreakAndContinue")
+	_types.RegisterFunc[func(_fn1 int)]("github.com/stealthrocket/coroutine/compiler/testdata.MethodGenerator")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.MultipleCallsInExpression")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.MutuallyRecursiveYield")
+	_types.RegisterFunc[func(_fn0 int) (_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.NestedLoops")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.NestedLoopsYieldingAtEachLevel")
+	_types.RegisterFunc[func(_fn0 [][]int)]("github.com/stealthrocket/coroutine/compiler/testdata.NestedRangeContinueOuter")
+	_types.RegisterFunc[func(_fn0 int, _fn1 func(int))]("github.com/stealthrocket/coroutine/compiler/testdata.Range")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureCapturingPointers")
+	_types.RegisterClosure[func() (_ bool), struct {
+		F  uintptr
+		X0 *struct {
+			IP int
+			X0 int
+			X1 int
+			X2 *int
+			X3 *int
+			X4 func() bool
+			X5 bool
+			X6 bool
+		}
+	}]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureCapturingPointers.func2")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureCapturingValues")
+	_types.RegisterClosure[func() (_ bool), struct {
+		F  uintptr
+		X0 *struct {
+			IP int
+			X0 int
+			X1 int
+			X2 func() bool
+			X3 bool
+			X4 bool
+		}
+	}]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureCapturingValues.func2")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureHeterogenousCapture")
+	_types.RegisterClosure[func() int, struct {
+		F  uintptr
+		X0 *struct {
+			IP  int
+			X0  int8
+			X1  int16
+			X2  int32
+			X3  int64
+			X4  uint8
+			X5  uint16
+			X6  uint32
+			X7  uint64
 			X8  uintptr
 			X9  func() int
 			X10 int
@@ -3315,7 +7595,11 @@ func init() {
 	}]("github.com/stealthrocket/coroutine/compiler/testdata.Range10ClosureHeterogenousCapture.func3")
 	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.Range10Heterogenous")
 	_types.RegisterFunc[func(_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeArrayIndexValueGenerator")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeOverChannel")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeOverMapDeletingKeys")
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeOverMaps")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeOverSliceFromCall")
+	_types.RegisterFunc[func(_fn0 string)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeOverStrings")
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeReverseClosureCaptureByValue")
 	_types.RegisterClosure[func(), struct {
 		F  uintptr
@@ -3332,15 +7616,43 @@ func init() {
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeTripleFuncValue")
 	_types.RegisterFunc[func(i int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeTripleFuncValue.func2")
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RangeYieldAndDeferAssign")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RecoverAfterYield")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RecursiveYield")
+	_types.RegisterFunc[func(_fn0 int) (_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.ReturnAfterCleanup")
 	_types.RegisterFunc[func() (_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.ReturnNamedValue")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.RunningTotal")
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.Select")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.SelectSendRecvDefault")
 	_types.RegisterFunc[func(_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.Shadowing")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.ShortCircuitEvaluation")
 	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.SomeFunctionThatShouldExistInTheCompiledFile")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.SortWithClosure")
+	_types.RegisterClosure[func(i, j int) bool, struct {
+		F  uintptr
+		X0 *struct {
+			IP int
+			X0 int
+			X1 []int
+			X2 []int
+			X3 int
+			X4 []int
+			X5 int
+			X6 int
+		}
+	}]("github.com/stealthrocket/coroutine/compiler/testdata.SortWithClosure.func2")
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.SquareGenerator")
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.SquareGeneratorTwice")
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.SquareGeneratorTwiceLoop")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.StructSendGenerator")
+	_types.RegisterFunc[func(_fn0 int) (_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.SumSentValues")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.SwitchFallthrough")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.SwitchInLoopContinue")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.SwitchWithInitStatement")
+	_types.RegisterFunc[func(_fn0 ...any)]("github.com/stealthrocket/coroutine/compiler/testdata.TypeSwitchYieldInCase")
 	_types.RegisterFunc[func(_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.TypeSwitchingGenerator")
+	_types.RegisterFunc[func(_fn1 int)]("github.com/stealthrocket/coroutine/compiler/testdata.ValueMethodGenerator")
 	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.VarArgs")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.YieldAcrossDefers")
 	_types.RegisterFunc[func(_fn0 *int, _fn1, _fn2 int)]("github.com/stealthrocket/coroutine/compiler/testdata.YieldAndDeferAssign")
 	_types.RegisterClosure[func(), struct {
 		F  uintptr
@@ -3352,6 +7664,11 @@ func init() {
 			X3 []func()
 		}
 	}]("github.com/stealthrocket/coroutine/compiler/testdata.YieldAndDeferAssign.func2")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.YieldDepth")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.YieldInExpression")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.YieldIndexAndSelectorExpressions")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.YieldWhilePanicking")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.YieldWhilePanicking.func2")
 	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.YieldingDurations")
 	_types.RegisterClosure[func(), struct {
 		F  uintptr
@@ -3366,5 +7683,42 @@ func init() {
 	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.YieldingExpressionDesugaring")
 	_types.RegisterFunc[func(_fn0 int) (_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.a")
 	_types.RegisterFunc[func(_fn0 int) (_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.b")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.close")
+	_types.RegisterFunc[func(_fn0 *int)]("github.com/stealthrocket/coroutine/compiler/testdata.deferredCounter")
+	_types.RegisterClosure[func(), struct {
+		F  uintptr
+		X0 *struct {
+			IP int
+			X0 *int
+			X1 []func()
+		}
+	}]("github.com/stealthrocket/coroutine/compiler/testdata.deferredCounter.func2")
+	_types.RegisterClosure[func(), struct {
+		F  uintptr
+		X0 *struct {
+			IP int
+			X0 *int
+			X1 []func()
+		}
+	}]("github.com/stealthrocket/coroutine/compiler/testdata.deferredCounter.func3")
+	_types.RegisterFunc[func(a, b int) (int, int)]("github.com/stealthrocket/coroutine/compiler/testdata.divmod")
+	_types.RegisterFunc[func(v int) int]("github.com/stealthrocket/coroutine/compiler/testdata.double")
+	_types.RegisterFunc[func(_fn0 *int) (_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.incrementAndYield")
+	_types.RegisterFunc[func(_fn0 int)]("github.com/stealthrocket/coroutine/compiler/testdata.mutuallyRecursiveYield")
+	_types.RegisterFunc[func(n int) []int]("github.com/stealthrocket/coroutine/compiler/testdata.rangeOverSlice")
+	_types.RegisterFunc[func(_fn0 int) (_fn1 int)]("github.com/stealthrocket/coroutine/compiler/testdata.recoverPanic")
+	_types.RegisterClosure[func(), struct {
+		F  uintptr
+		X0 *struct {
+			IP int
+			X0 int
+			X1 int
+			X2 []func()
+		}
+	}]("github.com/stealthrocket/coroutine/compiler/testdata.recoverPanic.func2")
 	_types.RegisterFunc[func(_fn0 ...int)]("github.com/stealthrocket/coroutine/compiler/testdata.varArgs")
+	_types.RegisterFunc[func(_fn0 int) (_ int)]("github.com/stealthrocket/coroutine/compiler/testdata.yieldAndReturn")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.yieldDepth")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.yieldDepth2")
+	_types.RegisterFunc[func()]("github.com/stealthrocket/coroutine/compiler/testdata.yieldDepth3")
 }
@@ -1,5 +1,11 @@
 //go:build durable
 
+// Code generated by coroc. DO NOT EDIT.
+//
+// This file holds the durable version of testdata.go. It is only built
+// with -tags durable, and the original source file is only built without
+// it (//go:build !durable), so the package compiles in both modes.
+
 package testdata
 
 const (
@@ -59,6 +59,12 @@ func typeExpr(p *packages.Package, typ types.Type) ast.Expr {
 		var namedExpr ast.Expr
 		if pkg == nil || p.Types == pkg {
 			namedExpr = name
+			if pkg != nil && obj.Parent() != pkg.Scope() {
+				// Types declared in function bodies are renamed when
+				// hoisted, record the use so the identifier is renamed
+				// as well.
+				p.TypesInfo.Uses[name] = obj
+			}
 		} else {
 			// Update the package's type map to track that this package is
 			// imported with this identifier. We do not attempt to reuse
@@ -1,72 +1,72 @@
 package compiler
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
+
+	"golang.org/x/tools/go/packages"
 )
 
+// checkUnsupported checks the coroutine functions of a package for
+// unsupported language features. All the errors found are joined, so
+// they can be reported at once.
+func checkUnsupported(p *packages.Package, colors functionColors) error {
+	coroutines := map[ast.Node]bool{}
+	for fn := range colors {
+		coroutines[fn.Syntax()] = true
+	}
+	var errs []error
+	for _, f := range p.Syntax {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && coroutines[fn] {
+				if err := unsupported(fn, p.Fset, p.TypesInfo); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // unsupported checks a function for unsupported language features.
-func unsupported(decl ast.Node, info *types.Info) (err error) {
+// Errors are prefixed with the position of the offending node, and all
+// the errors found in the function are joined.
+func unsupported(decl ast.Node, fset *token.FileSet, info *types.Info) error {
+	var errs []error
 	ast.Inspect(decl, func(node ast.Node) bool {
 		switch nn := node.(type) {
 		case ast.Stmt:
 			switch n := nn.(type) {
 			// Not yet supported:
 			case *ast.GoStmt:
-				err = fmt.Errorf("not implemented: go")
+				errs = append(errs, fmt.Errorf("%s: not implemented: go", fset.Position(n.Pos())))
 
 			// Partially supported:
-			case *ast.BranchStmt:
-				// continue/break are supported, goto/fallthrough are not.
-				if n.Tok == token.GOTO {
-					err = fmt.Errorf("not implemented: goto")
-				} else if n.Tok == token.FALLTHROUGH {
-					err = fmt.Errorf("not implemented: fallthrough")
-				}
-			case *ast.LabeledStmt:
-				// Labeled for/switch/select statements are supported,
-				// arbitrary labels are not.
-				switch n.Stmt.(type) {
-				case *ast.ForStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
-				default:
-					err = fmt.Errorf("not implemented: labels not attached to for/switch/select")
-				}
-			case *ast.ForStmt:
-				// Only simple post iteration statements are supported.
-				var exprs []ast.Expr
-				switch p := n.Post.(type) {
-				case nil:
-				case *ast.IncDecStmt:
-					exprs = append(exprs, p.X)
-				case *ast.AssignStmt:
-					if len(p.Lhs) != len(p.Rhs) {
-						err = fmt.Errorf("not implemented: for loop post iteration assignment with unbalanced sides")
-					}
-					exprs = append(exprs, p.Lhs...)
-					exprs = append(exprs, p.Rhs...)
-				default:
-					err = fmt.Errorf("not implemented: for loop post iteration statement %T", p)
-				}
-				for _, e := range exprs {
-					if countFunctionCalls(e, info) > 0 {
-						err = fmt.Errorf("not implemented: for loop post iteration statement with function call")
-					}
+			case *ast.RangeStmt:
+				// Range-over-func iterators require Go 1.23, while the
+				// module targets Go 1.21; the loop body would have to be
+				// lowered into a closure whose state lives in the frame.
+				if _, ok := info.TypeOf(n.X).Underlying().(*types.Signature); ok {
+					errs = append(errs, fmt.Errorf("%s: not implemented: for range over func", fset.Position(n.Pos())))
 				}
 
 			// Fully supported:
 			case *ast.AssignStmt:
 			case *ast.BlockStmt:
+			case *ast.BranchStmt:
 			case *ast.CaseClause:
 			case *ast.CommClause:
 			case *ast.DeclStmt:
 			case *ast.DeferStmt:
 			case *ast.EmptyStmt:
 			case *ast.ExprStmt:
+			case *ast.ForStmt:
 			case *ast.IfStmt:
 			case *ast.IncDecStmt:
-			case *ast.RangeStmt:
+			case *ast.LabeledStmt:
 			case *ast.ReturnStmt:
 			case *ast.SelectStmt:
 			case *ast.SendStmt:
@@ -75,12 +75,12 @@ func unsupported(decl ast.Node, info *types.Info) (err error) {
 
 			// Catch all in case new statements are added:
 			default:
-				err = fmt.Errorf("not implmemented: ast.Stmt(%T)", n)
+				errs = append(errs, fmt.Errorf("%s: not implemented: ast.Stmt(%T)", fset.Position(n.Pos()), n))
 			}
 		}
-		return err == nil
+		return true
 	})
-	return
+	return errors.Join(errs...)
 }
 
 func countFunctionCalls(expr ast.Expr, info *types.Info) (count int) {
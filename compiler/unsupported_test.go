@@ -0,0 +1,61 @@
+package compiler
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestUnsupportedPositions(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		body   string
+		expect string
+	}{
+		{
+			name:   "go statement",
+			body:   "\tgo g()\n",
+			expect: "foo.go:6:2: not implemented: go",
+		},
+		{
+			name:   "multiple unsupported features",
+			body:   "\tgo g()\n\tfor i := 0; i < 3; i += h() {\n\t\tgo g()\n\t}\n",
+			expect: "foo.go:6:2: not implemented: go\nfoo.go:8:3: not implemented: go",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			src := "package foo\n\nfunc g() {}\nfunc h() int { return 1 }\nfunc f() {\n" + test.body + "}\n"
+
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "foo.go", src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			info := &types.Info{
+				Types: map[ast.Expr]types.TypeAndValue{},
+				Defs:  map[*ast.Ident]types.Object{},
+				Uses:  map[*ast.Ident]types.Object{},
+			}
+			if _, err := new(types.Config).Check("foo", fset, []*ast.File{f}, info); err != nil {
+				t.Fatal(err)
+			}
+
+			var decl *ast.FuncDecl
+			for _, d := range f.Decls {
+				if fn, ok := d.(*ast.FuncDecl); ok && fn.Name.Name == "f" {
+					decl = fn
+				}
+			}
+
+			err = unsupported(decl, fset, info)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if err.Error() != test.expect {
+				t.Errorf("unexpected error:\ngot:  %s\nwant: %s", err, test.expect)
+			}
+		})
+	}
+}
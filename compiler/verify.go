@@ -0,0 +1,48 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// verify type-checks the durable build of the packages matching pattern
+// in dir, with the generated files overlaid on the filesystem.
+//
+// Only the errors of the packages in pkgs, which the files were generated
+// for, are reported.
+func verify(dir, pattern string, pkgs []*packages.Package, files map[string][]byte) error {
+	log.Printf("verifying generated code")
+	conf := &packages.Config{
+		Mode: packages.NeedName | packages.NeedImports | packages.NeedDeps |
+			packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes,
+		Dir:        dir,
+		Env:        os.Environ(),
+		BuildFlags: []string{"-tags=durable"},
+		Overlay:    files,
+	}
+	loaded, err := packages.Load(conf, pattern)
+	if err != nil {
+		return fmt.Errorf("packages.Load %q: %w", pattern, err)
+	}
+
+	generated := make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		generated[p.PkgPath] = true
+	}
+	var errs []error
+	packages.Visit(loaded, nil, func(p *packages.Package) {
+		if generated[p.PkgPath] {
+			for _, e := range p.Errors {
+				errs = append(errs, e)
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("generated code does not compile (this is a bug in the coroutine compiler):\n%w", errors.Join(errs...))
+	}
+	return nil
+}
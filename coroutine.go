@@ -24,6 +24,20 @@ func (c Coroutine[R, S]) Recv() R { return c.ctx.recv }
 // by the coroutine.
 func (c Coroutine[R, S]) Send(v S) { c.ctx.send = v }
 
+// Resume sends v to the coroutine and resumes its execution, it is equivalent
+// to calling Send then Next. The value is returned from the Yield call at which
+// the coroutine is paused, and the value that the coroutine yields next can be
+// retrieved with Recv if Resume returns true.
+//
+// The coroutine must have been started by a call to Next, otherwise there is
+// no yield point to receive v. Since sent values are not part of the state of
+// a durable coroutine, a coroutine reconstructed with Context.Unmarshal is
+// resumed by calling Resume with the value that it should receive.
+func (c Coroutine[R, S]) Resume(v S) bool {
+	c.Send(v)
+	return c.Next()
+}
+
 // Result is the return value of the coroutine, if it was constructed with
 // NewWithReturn. Result should only be called once Next returns false,
 // indicating that the coroutine finished executing.
@@ -38,7 +52,7 @@ func (c Coroutine[R, S]) Result() R { return c.ctx.result }
 //
 // This method is just an interrupt mechanism, the program does not have to call
 // it to release the coroutine resources after completion.
-func (c Coroutine[R, S]) Stop() { c.ctx.stop = true }
+func (c Coroutine[R, S]) Stop() { c.ctx.Cancel() }
 
 // Done returns true if the coroutine completed, either because it was stopped
 // or because its function returned.
@@ -66,9 +80,47 @@ type Context[R, S any] struct {
 	stop   bool
 	resume bool //nolint
 
+	// True if the coroutine completed by unwinding its call stack after
+	// being stopped, rather than by returning from its function.
+	cancelled bool
+
 	context[R]
 }
 
+// Done returns true if the coroutine associated with the context completed,
+// either because it was stopped or because its function returned. This is the
+// same as calling Done on the Coroutine.
+func (c *Context[R, S]) Done() bool { return c.done }
+
+// Result returns the value returned by the coroutine associated with the
+// context, if it was constructed with NewWithReturn. Result should only be
+// called once Done returns true, before that it returns the zero value of R.
+func (c *Context[R, S]) Result() R { return c.result }
+
+// Cancel interrupts the coroutine associated with the context, see Stop. The
+// method must be called by the program driving the coroutine while it is
+// suspended at a yield point, or before the first call to Next, not by the
+// coroutine itself. A coroutine cancelled before it started completes on the
+// next call to Next without running its function.
+//
+// The cancellation is part of the state of durable coroutines: a coroutine
+// cancelled and then serialized with Marshal is restored by Unmarshal as a
+// cancelled coroutine, which unwinds its call stack on the next call to Next.
+//
+// Calling Cancel after completion of the coroutine has no effect.
+func (c *Context[R, S]) Cancel() {
+	if !c.done {
+		c.stop = true
+	}
+}
+
+// Cancelled returns true if the coroutine completed because it was cancelled
+// or stopped, rather than because its function returned. It returns false
+// while the coroutine has not finished unwinding its call stack, and if its
+// function returned without reaching another yield point after Cancel was
+// called.
+func (c *Context[R, S]) Cancelled() bool { return c.cancelled }
+
 // Run executes a coroutine to completion, calling f for each value that the
 // coroutine yields, and sending back each value that f returns.
 func Run[R, S any](c Coroutine[R, S], f func(R) S) {
@@ -91,6 +143,9 @@ func Run[R, S any](c Coroutine[R, S], f func(R) S) {
 // Yield sends v to the generator and pauses the execution of the coroutine
 // until the Next method is called on the associated generator.
 //
+// Generators that never receive values back from their caller should use
+// struct{} as the send type, e.g. Yield[int, struct{}](v).
+//
 // The function panics when called on a stack where no active coroutine exists,
 // or if the type parameters do not match those of the coroutine.
 func Yield[R, S any](v R) S {
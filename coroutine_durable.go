@@ -89,20 +89,51 @@ func (s *Stack) isTop() bool {
 	return s.FP == len(s.Frames)-1
 }
 
-type serializedCoroutine[R any] struct {
+// Depth returns the number of functions with a frame on the coroutine call
+// stack (len(c.Stack.Frames)). It is not the logical call
+// depth: functions that the compiler did not allocate a frame for (e.g.
+// because they have no state to save across yields) are not counted.
+//
+// When called from the coroutine, it is the depth of the calling function.
+// When called after a yield, it is the depth of the function that yielded.
+// Before the coroutine started, the depth is zero.
+//
+// The call stack is only tracked in durable mode. In volatile mode, the
+// method always returns zero, which is indistinguishable from a coroutine
+// that has not started; check Durable to tell the two cases apart.
+func (c *Context[R, S]) Depth() int {
+	return len(c.Stack.Frames)
+}
+
+type serializedCoroutine[R, S any] struct {
 	entry  func()
 	entryR func() R
 	stack  Stack
 	resume bool
+	stop   bool
+
+	// Completion state, so that a completed coroutine is not restarted.
+	done      bool
+	cancelled bool
+
+	// The zero-length arrays record the yield types of the coroutine in
+	// the serialized state without taking any space, see
+	// (*types.State).YieldTypes.
+	recv [0]R
+	send [0]S
 }
 
 // Marshal returns a serialized Context.
 func (c *Context[R, S]) Marshal() ([]byte, error) {
-	return types.Serialize(&serializedCoroutine[R]{
+	return types.Serialize(&serializedCoroutine[R, S]{
 		entry:  c.entry,
 		entryR: c.entryR,
 		stack:  c.Stack,
 		resume: c.resume,
+		stop:   c.stop,
+
+		done:      c.done,
+		cancelled: c.cancelled,
 	})
 }
 
@@ -117,15 +148,21 @@ func (c *Context[R, S]) Unmarshal(b []byte) error {
 		}
 		return err
 	}
-	s := v.(*serializedCoroutine[R])
+	s := v.(*serializedCoroutine[R, S])
 	c.entry = s.entry
 	c.entryR = s.entryR
 	c.Stack = s.stack
 	c.resume = s.resume
+	c.stop = s.stop
+	c.done = s.done
+	c.cancelled = s.cancelled
 	return nil
 }
 
 func (c *Context[R, S]) Yield(value R) S {
+	if c.panicking != nil && c.panicking.value != nil {
+		panic("cannot yield from a deferred function while a panic is in flight")
+	}
 	if c.resume {
 		c.resume = false
 		if c.stop {
@@ -152,12 +189,19 @@ func (c Coroutine[R, S]) Next() (hasNext bool) {
 	if c.ctx.done {
 		return false
 	}
+	if c.ctx.stop && !c.ctx.resume {
+		// The coroutine was stopped before it started.
+		c.ctx.done, c.ctx.cancelled = true, true
+		return false
+	}
 
 	execute(c.ctx, func() {
 		defer func() {
+			var unwound bool
 			switch v := recover().(type) {
 			case nil:
 			case unwind:
+				unwound = true
 			default:
 				// TODO: can we figure out a way to know when we are unwinding the
 				// stack and only recover then so we don't alter the panic stack?
@@ -169,6 +213,7 @@ func (c Coroutine[R, S]) Next() (hasNext bool) {
 				c.ctx.done, hasNext = stop, !stop
 			} else {
 				c.ctx.done = true
+				c.ctx.cancelled = unwound && c.ctx.stop
 			}
 		}()
 
@@ -197,6 +242,14 @@ type context[R any] struct {
 	entry  func()
 	entryR func() R
 	Stack
+
+	// Panic in flight while running deferred functions, see RunDefers.
+	panicking *panicking
+}
+
+type panicking struct {
+	value     any
+	recovered bool
 }
 
 type unwind struct{}
@@ -206,6 +259,60 @@ func (c *Context[R, S]) Unwinding() bool {
 	return c.resume
 }
 
+// RunDefers runs the functions deferred by a coroutine function, in reverse
+// order, when the function returns or panics. The value p is the result of
+// calling recover in the function that the Go runtime deferred, and is nil
+// if the function did not panic.
+//
+// Functions deferred by a coroutine function are not called by the Go
+// runtime directly, so calling recover from them would always return nil.
+// The compiler rewrites those calls to Recover instead, which returns p
+// while the deferred functions are running. If none of them recovers the
+// panic, it is raised again after they ran; otherwise, RunDefers returns
+// true and the function returns normally with the current values of its
+// named results.
+//
+// The panic in flight is not part of the serialized state of the coroutine,
+// deferred functions must not yield while a panic is in flight. Yield panics
+// if they do, even if the panic was recovered.
+func (c *Context[R, S]) RunDefers(defers []func(), p any) (recovered bool) {
+	state := &panicking{value: p}
+	prev := c.panicking
+	c.panicking = state
+
+	defer func() {
+		c.panicking = prev
+		if v := recover(); v != nil {
+			panic(v) // a deferred function panicked
+		}
+		if p != nil && !state.recovered {
+			panic(p)
+		}
+		recovered = p != nil
+	}()
+
+	for _, f := range defers {
+		defer f()
+	}
+	return
+}
+
+// Recover is called in place of recover by functions deferred by coroutine
+// functions, see RunDefers.
+func (c *Context[R, S]) Recover() any {
+	p := c.panicking
+	if p == nil || p.recovered {
+		return nil
+	}
+	if _, ok := p.value.(unwind); ok {
+		// The coroutine is being stopped, the panic must propagate to
+		// the entry point.
+		return nil
+	}
+	p.recovered = true
+	return p.value
+}
+
 // The load function returns the value passed as first argument to the call to
 // execute that started the coroutine.
 func load() any {
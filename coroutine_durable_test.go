@@ -5,6 +5,8 @@ package coroutine
 import (
 	"reflect"
 	"testing"
+
+	"github.com/stealthrocket/coroutine/types"
 )
 
 func TestLocalStorageStack(t *testing.T) {
@@ -36,3 +38,60 @@ func TestLocalStorageStack(t *testing.T) {
 		t.Error("test did not run")
 	}
 }
+
+func yieldTypesEntry() {}
+
+func TestYieldTypes(t *testing.T) {
+	types.RegisterFunc[func()]("github.com/stealthrocket/coroutine.yieldTypesEntry")
+
+	c := New[int, []string](yieldTypesEntry)
+	b, err := c.Context().Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := types.Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recv, send := s.YieldTypes()
+	if recv == nil || send == nil {
+		t.Fatal("yield types not found")
+	}
+	if got := recv.Kind(); got != reflect.Int {
+		t.Errorf("unexpected recv type: got %s, expect int", got)
+	}
+	if got := send.Kind(); got != reflect.Slice || send.Elem().Kind() != reflect.String {
+		t.Errorf("unexpected send type: got %s, expect []string", got)
+	}
+
+	// State that was not generated by a coroutine has no yield types.
+	b, err = types.Serialize(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = types.Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recv, send := s.YieldTypes(); recv != nil || send != nil {
+		t.Errorf("unexpected yield types: %v, %v", recv, send)
+	}
+
+	// Neither has state holding a value shaped like a coroutine.
+	type lookalike struct {
+		recv [0]int
+		send [0]string
+	}
+	b, err = types.Serialize(&lookalike{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = types.Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recv, send := s.YieldTypes(); recv != nil || send != nil {
+		t.Errorf("unexpected yield types: %v, %v", recv, send)
+	}
+}
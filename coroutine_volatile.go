@@ -37,7 +37,10 @@ func NewWithReturn[R, S any](f func() R) Coroutine[R, S] {
 
 			<-c.next
 
-			if !c.stop {
+			if c.stop {
+				// The coroutine was stopped before it started.
+				c.cancelled = true
+			} else {
 				c.result = f()
 			}
 		})
@@ -73,11 +76,18 @@ func (c *Context[R, S]) Yield(v R) S {
 	c.next <- struct{}{}
 	<-c.next
 	if c.stop {
+		c.cancelled = true
 		runtime.Goexit()
 	}
 	return c.send
 }
 
+// Depth always returns zero, since the call stack of coroutines is not
+// tracked in volatile mode (see Durable).
+func (c *Context[R, S]) Depth() int {
+	return 0
+}
+
 func (c *Context[R, S]) Marshal() ([]byte, error) {
 	return nil, ErrNotDurable
 }
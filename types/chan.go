@@ -0,0 +1,66 @@
+package types
+
+import (
+	"errors"
+	"sync/atomic"
+	"unsafe"
+)
+
+// The hchan type is used for unsafe access to the buffer of a channel. It
+// declares the leading fields of the hchan struct of the Go runtime, whose
+// layout depends on the version of Go (see chan_go121.go and
+// chan_go123.go).
+//
+// The runtime lock of the channel cannot be acquired from outside of the
+// runtime, so the buffer is read without holding it. Channels must not be
+// used by other goroutines while they are being serialized; the indexes
+// of the channel are checked after reading its buffer to detect it. The
+// errors below are returned by Serialize and SerializeTo.
+
+// errChanLayout is raised when serializing a channel if the layout of the
+// channels of the Go runtime is not the one expected.
+var errChanLayout = errors.New("cannot serialize channels: unsupported layout of channels in this version of Go")
+
+// errChanModified is raised when a channel is modified while its buffered
+// values are being serialized.
+var errChanModified = errors.New("channel was modified concurrently while being serialized")
+
+// hchanLayoutOK is true if the layout of hchan was verified to match the
+// one of the Go runtime, see checkChanLayout.
+var hchanLayoutOK = checkChanLayout()
+
+// checkChanLayout verifies that the fields of hchan reflect the state of a
+// channel with a known state, so that an unknown version of Go with a
+// different layout is detected instead of corrupting serialized channels.
+func checkChanLayout() bool {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	<-ch
+	c := (*hchan)(*(*unsafe.Pointer)(unsafe.Pointer(&ch)))
+	ok := c.qcount == 1 && c.dataqsiz == 3 && c.elemsize == 8 &&
+		c.closed == 0 && c.sendx == 2 && c.recvx == 1 && *(*int)(c.elem(0)) == 2
+	close(ch)
+	return ok && c.closed != 0
+}
+
+// chanState is the part of the state of a channel that must not change
+// while its buffer is being read.
+type chanState struct {
+	qcount, sendx, recvx uint
+}
+
+func (c *hchan) state() chanState {
+	return chanState{
+		qcount: uint(atomic.LoadUintptr((*uintptr)(unsafe.Pointer(&c.qcount)))),
+		sendx:  uint(atomic.LoadUintptr((*uintptr)(unsafe.Pointer(&c.sendx)))),
+		recvx:  uint(atomic.LoadUintptr((*uintptr)(unsafe.Pointer(&c.recvx)))),
+	}
+}
+
+// elem returns a pointer to the i-th element buffered in the channel, in
+// the order they would be received.
+func (c *hchan) elem(i int) unsafe.Pointer {
+	j := (c.recvx + uint(i)) % c.dataqsiz
+	return unsafe.Add(c.buf, uintptr(j)*uintptr(c.elemsize))
+}
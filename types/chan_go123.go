@@ -0,0 +1,22 @@
+//go:build go1.23
+
+package types
+
+import "unsafe"
+
+// Used for unsafe access to the buffer of a channel, see chan.go.
+//
+// Go 1.23 added the timer field, which precedes the element type.
+//
+// See: https://github.com/golang/go/blob/go1.23.0/src/runtime/chan.go
+type hchan struct {
+	qcount   uint           // total data in the queue
+	dataqsiz uint           // size of the circular queue
+	buf      unsafe.Pointer // points to an array of dataqsiz elements
+	elemsize uint16
+	closed   uint32
+	timer    unsafe.Pointer
+	elemtype unsafe.Pointer
+	sendx    uint // send index
+	recvx    uint // receive index
+}
@@ -1,12 +1,25 @@
 package types
 
 import (
+	"encoding/gob"
+	"errors"
 	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 func init() {
 	Register[time.Time](serializeTime, deserializeTime)
+	Register[big.Int](serializeGob[big.Int], deserializeGob[big.Int])
+	Register[big.Rat](serializeGob[big.Rat], deserializeGob[big.Rat])
+	Register[big.Float](serializeGob[big.Float], deserializeGob[big.Float])
+	Register[sync.Mutex](serializeMutex, deserializeMutex)
+	Register[sync.Once](serializeOnce, deserializeOnce)
+	Register[sync.WaitGroup](serializeWaitGroup, deserializeWaitGroup)
 }
 
 func serializeTime(s *Serializer, x *time.Time) error {
@@ -22,5 +35,165 @@ func serializeTime(s *Serializer, x *time.Time) error {
 func deserializeTime(d *Deserializer, x *time.Time) error {
 	var b []byte
 	DeserializeTo(d, &b)
-	return x.UnmarshalBinary(b)
+	if err := x.UnmarshalBinary(b); err != nil {
+		return fmt.Errorf("failed to unmarshal time.Time: %w", err)
+	}
+	return nil
+}
+
+// gobCodec is the constraint of pointer types implementing the gob
+// encoding interfaces, such as the types of the math/big package.
+type gobCodec[T any] interface {
+	*T
+	gob.GobEncoder
+	gob.GobDecoder
+}
+
+func serializeGob[T any, P gobCodec[T]](s *Serializer, x *T) error {
+	data, err := P(x).GobEncode()
+	if err != nil {
+		return fmt.Errorf("failed to marshal %T: %w", x, err)
+	}
+
+	SerializeT(s, data)
+	return nil
+}
+
+func deserializeGob[T any, P gobCodec[T]](d *Deserializer, x *T) error {
+	var b []byte
+	DeserializeTo(d, &b)
+	if err := P(x).GobDecode(b); err != nil {
+		return fmt.Errorf("failed to unmarshal %T: %w", x, err)
+	}
+	return nil
+}
+
+// Values of the sync package hold runtime state (semaphores, waiters) that
+// cannot survive a deserialization, so only the state observable through
+// their API is serialized. Those defaults can be overridden by calling
+// [Register] for the same types.
+//
+// The state is read from unexported fields, without altering the values.
+// Their layout is verified when the package is initialized, and values
+// cannot be serialized if it does not match (e.g. in a newer version of Go
+// than the ones known to this package).
+
+// errMutexLocked is returned when serializing a locked sync.Mutex. The
+// goroutine holding the lock does not exist after deserialization, so the
+// lock could never be released.
+var errMutexLocked = errors.New("cannot serialize a locked sync.Mutex")
+
+// errSyncLayout is returned when serializing a value of the sync package
+// whose layout is not the one expected.
+var errSyncLayout = errors.New("unsupported layout of sync values in this version of Go")
+
+// The locked bit is the lowest bit of the state of a sync.Mutex, which is
+// its first field.
+const mutexLocked = 1
+
+var (
+	mutexLayoutOK = checkMutexLayout()
+
+	onceDoneOffset, onceLayoutOK            = syncFieldOffset[sync.Once]("done")
+	waitGroupStateOffset, waitGroupLayoutOK = syncFieldOffset[sync.WaitGroup]("state")
+)
+
+func init() {
+	onceLayoutOK = onceLayoutOK && checkOnceLayout()
+	waitGroupLayoutOK = waitGroupLayoutOK && checkWaitGroupLayout()
+}
+
+func syncFieldOffset[T any](name string) (uintptr, bool) {
+	f, ok := reflect.TypeOf((*T)(nil)).Elem().FieldByName(name)
+	return f.Offset, ok
+}
+
+func isMutexLocked(x *sync.Mutex) bool {
+	return atomic.LoadInt32((*int32)(unsafe.Pointer(x)))&mutexLocked != 0
+}
+
+func checkMutexLayout() bool {
+	var m sync.Mutex
+	unlocked := !isMutexLocked(&m)
+	m.Lock()
+	locked := isMutexLocked(&m)
+	m.Unlock()
+	return unlocked && locked && !isMutexLocked(&m)
+}
+
+func isOnceDone(x *sync.Once) bool {
+	return atomic.LoadUint32((*uint32)(unsafe.Add(unsafe.Pointer(x), onceDoneOffset))) != 0
+}
+
+func checkOnceLayout() bool {
+	var o sync.Once
+	notDone := !isOnceDone(&o)
+	o.Do(func() {})
+	return notDone && isOnceDone(&o)
+}
+
+// The counter is stored in the high 32 bits of the state of a
+// sync.WaitGroup.
+func waitGroupCounter(x *sync.WaitGroup) int32 {
+	state := atomic.LoadUint64((*uint64)(unsafe.Add(unsafe.Pointer(x), waitGroupStateOffset)))
+	return int32(state >> 32)
+}
+
+func checkWaitGroupLayout() bool {
+	var wg sync.WaitGroup
+	wg.Add(3)
+	ok := waitGroupCounter(&wg) == 3
+	wg.Add(-3)
+	return ok && waitGroupCounter(&wg) == 0
+}
+
+// A sync.Mutex is always deserialized unlocked, so nothing is written to
+// the serialized state.
+func serializeMutex(s *Serializer, x *sync.Mutex) error {
+	if !mutexLayoutOK {
+		return errSyncLayout
+	}
+	if isMutexLocked(x) {
+		return errMutexLocked
+	}
+	return nil
+}
+
+func deserializeMutex(d *Deserializer, x *sync.Mutex) error {
+	*x = sync.Mutex{}
+	return nil
+}
+
+func serializeOnce(s *Serializer, x *sync.Once) error {
+	if !onceLayoutOK {
+		return errSyncLayout
+	}
+	SerializeT(s, isOnceDone(x))
+	return nil
+}
+
+func deserializeOnce(d *Deserializer, x *sync.Once) error {
+	var done bool
+	DeserializeTo(d, &done)
+	*x = sync.Once{}
+	if done {
+		x.Do(func() {})
+	}
+	return nil
+}
+
+func serializeWaitGroup(s *Serializer, x *sync.WaitGroup) error {
+	if !waitGroupLayoutOK {
+		return errSyncLayout
+	}
+	SerializeT(s, waitGroupCounter(x))
+	return nil
+}
+
+func deserializeWaitGroup(d *Deserializer, x *sync.WaitGroup) error {
+	var counter int32
+	DeserializeTo(d, &counter)
+	*x = sync.WaitGroup{}
+	x.Add(int(counter))
+	return nil
 }
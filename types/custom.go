@@ -26,8 +26,12 @@ type DeserializerFunc[T any] func(*Deserializer, *T) error
 // and [Context.Unmarshal] respectively.
 //
 // Go basic types, structs, interfaces, slices, arrays, or any combination of
-// them have built-in serialization and deserialization mechanisms. Channels and
-// sync values do not.
+// them have built-in serialization and deserialization mechanisms.
+// time.Time, big.Int, big.Rat and big.Float are serialized with their
+// binary encoding. A sync.Mutex is serialized as unlocked (serializing a locked mutex is an
+// error), a sync.Once as its done flag, and a sync.WaitGroup as its counter.
+// Calling [Register] for a type that already has serialization functions,
+// including those sync types, replaces them.
 //
 // Custom serializer and deserializer functions can be attached to types using
 // [Register] to control how they are serialized, and possibly perform
@@ -0,0 +1,359 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// StateDiff is the difference between two states, as reported by Diff.
+type StateDiff struct {
+	// Build lists the properties of the build that differ between the
+	// two states (build ID, OS, architecture and Go version).
+	Build []PropertyDiff
+
+	// Types lists the types that are only present in one of the states,
+	// or whose layout differs. Types are matched by name.
+	Types []ItemDiff
+
+	// Functions lists the functions that are only present in one of the
+	// states, or whose type differs. Functions are matched by name.
+	Functions []ItemDiff
+
+	// Regions lists the regions that are only reachable in one of the
+	// states, or whose type or contents differ.
+	Regions []RegionDiff
+}
+
+// PropertyDiff is a property with different values in two states.
+type PropertyDiff struct {
+	Name string
+	A, B string
+}
+
+// ItemDiff is a named item that differs between two states. A or B is
+// empty if the item is missing from the corresponding state.
+type ItemDiff struct {
+	Name string
+	A, B string
+}
+
+// RegionDiff is a region that differs between two states.
+//
+// Regions are aligned by the path through which they are reachable from
+// the root region (e.g. root.Next.Next), rather than by their index,
+// since indexes depend on the order in which the serializer discovered
+// the regions. A or B is nil if the region is not reachable through the
+// same path in the corresponding state.
+type RegionDiff struct {
+	Path string
+	A, B *Region
+}
+
+// Empty is true if no differences were found.
+func (d *StateDiff) Empty() bool {
+	return len(d.Build) == 0 && len(d.Types) == 0 && len(d.Functions) == 0 && len(d.Regions) == 0
+}
+
+// String returns a human readable summary of the differences, one per
+// line.
+func (d *StateDiff) String() string {
+	var b strings.Builder
+	for _, p := range d.Build {
+		fmt.Fprintf(&b, "build %s: %q != %q\n", p.Name, p.A, p.B)
+	}
+	for _, t := range d.Types {
+		writeItemDiff(&b, "type", t)
+	}
+	for _, f := range d.Functions {
+		writeItemDiff(&b, "function", f)
+	}
+	for _, r := range d.Regions {
+		switch {
+		case r.A == nil:
+			fmt.Fprintf(&b, "region %s: only in b (%v)\n", r.Path, r.B.Type())
+		case r.B == nil:
+			fmt.Fprintf(&b, "region %s: only in a (%v)\n", r.Path, r.A.Type())
+		default:
+			ta, tb := fmt.Sprint(r.A.Type()), fmt.Sprint(r.B.Type())
+			if ta != tb {
+				fmt.Fprintf(&b, "region %s: type %s != %s\n", r.Path, ta, tb)
+			} else {
+				fmt.Fprintf(&b, "region %s: contents differ (%s)\n", r.Path, ta)
+			}
+		}
+	}
+	return b.String()
+}
+
+func writeItemDiff(b *strings.Builder, what string, d ItemDiff) {
+	switch {
+	case d.A == "":
+		fmt.Fprintf(b, "%s %s: only in b\n", what, d.Name)
+	case d.B == "":
+		fmt.Fprintf(b, "%s %s: only in a\n", what, d.Name)
+	default:
+		fmt.Fprintf(b, "%s %s: %s != %s\n", what, d.Name, d.A, d.B)
+	}
+}
+
+// Diff compares two states, typically produced by Serialize or
+// (*coroutine.Context).Marshal, and reports their differences.
+//
+// Regions are compared by their type and decoded contents, so pointers
+// are equal if they point at the same path from the root. Strings are
+// compared by value, and map entries regardless of their order. An error
+// is returned if the regions of either state cannot be scanned.
+func Diff(a, b *State) (*StateDiff, error) {
+	d := new(StateDiff)
+
+	for _, p := range []PropertyDiff{
+		{"id", a.BuildID(), b.BuildID()},
+		{"os", a.OS(), b.OS()},
+		{"arch", a.Arch(), b.Arch()},
+		{"go version", a.GoVersion(), b.GoVersion()},
+	} {
+		if p.A != p.B {
+			d.Build = append(d.Build, p)
+		}
+	}
+
+	d.Types = diffItems(diffTypes(a), diffTypes(b))
+	d.Functions = diffItems(diffFunctions(a), diffFunctions(b))
+
+	ra, err := walkRegions(a)
+	if err != nil {
+		return nil, fmt.Errorf("state a: %w", err)
+	}
+	rb, err := walkRegions(b)
+	if err != nil {
+		return nil, fmt.Errorf("state b: %w", err)
+	}
+	for _, path := range sortedKeys(ra, rb) {
+		x, y := ra[path], rb[path]
+		switch {
+		case x == nil:
+			d.Regions = append(d.Regions, RegionDiff{Path: path, B: y.region})
+		case y == nil:
+			d.Regions = append(d.Regions, RegionDiff{Path: path, A: x.region})
+		case x.contents != y.contents:
+			d.Regions = append(d.Regions, RegionDiff{Path: path, A: x.region, B: y.region})
+		}
+	}
+	return d, nil
+}
+
+func diffTypes(s *State) map[string]string {
+	types := make(map[string]string, s.NumType())
+	for i := 0; i < s.NumType(); i++ {
+		t := s.Type(i)
+		types[fmt.Sprint(t)] = fmt.Sprintf("%+v", t)
+	}
+	return types
+}
+
+func diffFunctions(s *State) map[string]string {
+	funcs := make(map[string]string, s.NumFunction())
+	for i := 0; i < s.NumFunction(); i++ {
+		f := s.Function(i)
+		var typ string
+		if f.function.Type != 0 {
+			typ = fmt.Sprint(f.Type())
+		}
+		funcs[f.Name()] = "(" + typ + ")"
+	}
+	return funcs
+}
+
+func diffItems(a, b map[string]string) (diffs []ItemDiff) {
+	for _, name := range sortedKeys(a, b) {
+		x, y := a[name], b[name]
+		if x != y {
+			diffs = append(diffs, ItemDiff{Name: name, A: x, B: y})
+		}
+	}
+	return diffs
+}
+
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// walkedRegion is a region reached by walkRegions, along with a
+// canonical representation of its type and contents.
+type walkedRegion struct {
+	region   *Region
+	contents string
+}
+
+// walkRegions walks the regions reachable from the root of s, breadth
+// first, and returns them keyed by the path through which they were
+// first reached.
+func walkRegions(s *State) (map[string]*walkedRegion, error) {
+	regions := map[string]*walkedRegion{}
+	if s.state.Root == nil {
+		return regions, nil
+	}
+
+	paths := map[int]string{-1: "root"}
+	queue := []*Region{s.Root()}
+
+	for len(queue) > 0 {
+		r := queue[0]
+		queue = queue[1:]
+		path := paths[r.Index()]
+
+		isMap := r.Type().Kind() == reflect.Map
+		used := map[string]int{}
+		var tokens []string
+		var entries []string
+		var key string
+		item := -1
+
+		scan := r.Scan()
+		for scan.Next() {
+			if i := scan.mapItem(); i != item {
+				// Entries of map regions are made of a key and a value,
+				// a new entry starts when the scanner reaches its key.
+				item = i
+				if item%2 == 0 {
+					if len(tokens) > 0 {
+						entries = append(entries, strings.Join(tokens, "\n"))
+						tokens = tokens[:0]
+					}
+					key = diffValue(scan, paths)
+				}
+			}
+
+			target, _ := scan.Region()
+			if target != nil && scan.Kind() != reflect.String {
+				if _, ok := paths[target.Index()]; !ok {
+					var elem string
+					switch f := scan.Field(); {
+					case f != nil && f.Name() != "":
+						elem = "." + f.Name()
+					case isMap:
+						elem = "[" + key + "]"
+					default:
+						elem = "[*]"
+					}
+					used[elem]++
+					if n := used[elem]; n > 1 {
+						elem += "#" + strconv.Itoa(n)
+					}
+					paths[target.Index()] = path + elem
+					queue = append(queue, target)
+				}
+			}
+			tokens = append(tokens, strconv.Itoa(scan.Depth())+" "+diffValue(scan, paths))
+		}
+		if err := scan.Close(); err != nil {
+			return nil, fmt.Errorf("scanning region %s: %w", path, err)
+		}
+
+		if isMap {
+			if len(tokens) > 0 {
+				entries = append(entries, strings.Join(tokens, "\n"))
+			}
+			sort.Strings(entries)
+			tokens = entries
+		}
+		regions[path] = &walkedRegion{
+			region:   r,
+			contents: fmt.Sprint(r.Type()) + "\n" + strings.Join(tokens, "\n"),
+		}
+	}
+	return regions, nil
+}
+
+// diffValue returns a representation of the value the scanner points to,
+// which does not depend on the indexes of types and regions.
+func diffValue(s *Scanner, paths map[int]string) string {
+	switch s.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(s.Bool())
+	case reflect.Int:
+		return strconv.Itoa(s.Int())
+	case reflect.Int8:
+		return strconv.Itoa(int(s.Int8()))
+	case reflect.Int16:
+		return strconv.Itoa(int(s.Int16()))
+	case reflect.Int32:
+		return strconv.Itoa(int(s.Int32()))
+	case reflect.Int64:
+		return strconv.FormatInt(s.Int64(), 10)
+	case reflect.Uint:
+		return strconv.FormatUint(uint64(s.Uint()), 10)
+	case reflect.Uint8:
+		return strconv.Itoa(int(s.Uint8()))
+	case reflect.Uint16:
+		return strconv.Itoa(int(s.Uint16()))
+	case reflect.Uint32:
+		return strconv.FormatUint(uint64(s.Uint32()), 10)
+	case reflect.Uint64:
+		return strconv.FormatUint(s.Uint64(), 10)
+	case reflect.Uintptr:
+		return strconv.FormatUint(uint64(s.Uintptr()), 10)
+	case reflect.Float32:
+		return fmt.Sprint(s.Float32())
+	case reflect.Float64:
+		return fmt.Sprint(s.Float64())
+	case reflect.Complex64:
+		return fmt.Sprint(s.Complex64())
+	case reflect.Complex128:
+		return fmt.Sprint(s.Complex128())
+	case reflect.String:
+		v, ok := s.stringValue()
+		if !ok {
+			return fmt.Sprintf("<invalid string of length %d>", s.Len())
+		}
+		return strconv.Quote(v)
+	case reflect.Func:
+		if s.Nil() {
+			return "nil"
+		}
+		return s.Function().Name()
+	case reflect.Struct, reflect.Array:
+		return fmt.Sprint(s.Type())
+	}
+
+	var prefix string
+	switch s.Kind() {
+	case reflect.Slice:
+		prefix = fmt.Sprintf("len=%d cap=%d ", s.Len(), s.Cap())
+	case reflect.Interface:
+		if !s.Nil() {
+			prefix = fmt.Sprintf("(%v) ", s.Type())
+		}
+	case reflect.Map:
+		if s.Depth() == 1 {
+			// Map regions start with the number of entries.
+			return fmt.Sprintf("len=%d", s.Len())
+		}
+	case reflect.Chan:
+		if s.Depth() == 1 {
+			// Channel regions start with the state of the channel.
+			return fmt.Sprintf("cap=%d len=%d closed=%t", s.Cap(), s.Len(), s.Closed())
+		}
+	}
+	switch r, offset := s.Region(); {
+	case s.Nil():
+		return prefix + "nil"
+	case r == nil:
+		return prefix + "static"
+	default:
+		return fmt.Sprintf("%s&%s+%d", prefix, paths[r.Index()], offset)
+	}
+}
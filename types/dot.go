@@ -0,0 +1,95 @@
+package types
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the graph of regions of the state to w, in the Graphviz
+// DOT language.
+//
+// Each region is a node labeled with its index, type and size. Edges
+// represent pointers from one region into another, and are labeled with
+// the name of the field holding the pointer (if any) and the offset into
+// the target region. The root region is highlighted.
+//
+// The output can be rendered with the dot tool, e.g. dot -Tsvg.
+func (s *State) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph coroutine {")
+	fmt.Fprintln(bw, "\tnode [shape=box];")
+
+	regions := make([]*Region, 0, 1+s.NumRegion())
+	if s.state.Root != nil {
+		regions = append(regions, s.Root())
+	}
+	for i := 0; i < s.NumRegion(); i++ {
+		regions = append(regions, s.Region(i))
+	}
+
+	for _, r := range regions {
+		label := fmt.Sprintf("%s\\n%s\\n%d byte(s)", dotNodeName(r), dotEscape(fmt.Sprint(r.Type())), r.Size())
+		if r.Index() < 0 {
+			fmt.Fprintf(bw, "\t%s [label=\"%s\", style=bold, color=red];\n", dotNodeName(r), label)
+		} else {
+			fmt.Fprintf(bw, "\t%s [label=\"%s\"];\n", dotNodeName(r), label)
+		}
+	}
+
+	type edge struct {
+		from, to int
+		label    string
+	}
+	seen := map[edge]struct{}{}
+
+	for _, r := range regions {
+		scan := r.Scan()
+		for scan.Next() {
+			target, offset := scan.Region()
+			if target == nil {
+				continue
+			}
+			var label string
+			if f := scan.Field(); f != nil && f.Name() != "" {
+				label = f.Name()
+			}
+			if offset != 0 {
+				if label != "" {
+					label += " "
+				}
+				label += fmt.Sprintf("+%d", offset)
+			}
+			e := edge{from: r.Index(), to: target.Index(), label: label}
+			if _, ok := seen[e]; ok {
+				continue
+			}
+			seen[e] = struct{}{}
+			if label != "" {
+				fmt.Fprintf(bw, "\t%s -> %s [label=\"%s\"];\n", dotNodeName(r), dotNodeName(target), dotEscape(label))
+			} else {
+				fmt.Fprintf(bw, "\t%s -> %s;\n", dotNodeName(r), dotNodeName(target))
+			}
+		}
+		if err := scan.Close(); err != nil {
+			return fmt.Errorf("scanning %s: %w", dotNodeName(r), err)
+		}
+	}
+
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func dotNodeName(r *Region) string {
+	if r.Index() < 0 {
+		return "root"
+	}
+	return fmt.Sprintf("r%d", r.Index())
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotEscape(s string) string {
+	return dotEscaper.Replace(s)
+}
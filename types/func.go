@@ -6,6 +6,7 @@ import (
 	"debug/gosym"
 	"errors"
 	"io"
+	"os"
 	"reflect"
 	"runtime"
 	"unsafe"
@@ -151,6 +152,16 @@ func initFunctionTables(pclntab, symtab []byte) {
 	}
 }
 
+// executable returns the path to the executable of the current process.
+// os.Args[0] is only used as a fallback since it is not a path when the
+// program was found by a lookup in $PATH.
+func executable() string {
+	if path, err := os.Executable(); err == nil {
+		return path
+	}
+	return os.Args[0]
+}
+
 func readSection(r io.ReaderAt, size uint64) ([]byte, error) {
 	if r == nil {
 		return nil, errors.New("section missing")
@@ -6,6 +6,7 @@ import (
 	"io"
 	"math"
 	"reflect"
+	"strings"
 
 	coroutinev1 "github.com/stealthrocket/coroutine/gen/proto/go/coroutine/v1"
 )
@@ -42,6 +43,13 @@ func (s *State) Arch() string {
 	return s.state.Build.Arch
 }
 
+// GoVersion returns the version of Go used to build the program that
+// generated this state, as reported by runtime.Version. It is empty
+// for state generated by programs that did not record it.
+func (s *State) GoVersion() string {
+	return s.state.Build.GoVersion
+}
+
 // NumType returns the number of types referenced by the coroutine.
 func (s *State) NumType() int {
 	return len(s.state.Types)
@@ -59,6 +67,29 @@ func (s *State) Type(i int) *Type {
 	}
 }
 
+// WalkTypes calls fn for each type referenced by the coroutine, in
+// index order, until fn returns false.
+func (s *State) WalkTypes(fn func(*Type) bool) {
+	for i := range s.state.Types {
+		if !fn(s.Type(i)) {
+			return
+		}
+	}
+}
+
+// FindType returns the type with the given package path and name, or
+// nil if the coroutine does not reference such a type.
+func (s *State) FindType(pkg, name string) (t *Type) {
+	s.WalkTypes(func(typ *Type) bool {
+		if typ.Package() == pkg && typ.Name() == name {
+			t = typ
+			return false
+		}
+		return true
+	})
+	return t
+}
+
 // NumFunction returns the number of functions/methods/closures
 // referenced by the coroutine.
 func (s *State) NumFunction() int {
@@ -95,6 +126,17 @@ func (s *State) Region(i int) *Region {
 	}
 }
 
+// WalkRegions calls fn for each memory region referenced by the
+// coroutine, in index order, until fn returns false. The root region is
+// not included.
+func (s *State) WalkRegions(fn func(*Region) bool) {
+	for i := range s.state.Regions {
+		if !fn(s.Region(i)) {
+			return
+		}
+	}
+}
+
 // NumString returns the number of strings referenced by types.
 func (s *State) NumString() int {
 	return len(s.state.Strings)
@@ -117,6 +159,57 @@ func (s *State) Root() *Region {
 	}
 }
 
+// DecodeRoot decodes the root object that was serialized.
+//
+// The state can only be decoded by the program that generated it. An error
+// wrapping ErrBuildIDMismatch is returned if the build ID of the state does
+// not match the build ID of the current program.
+func (s *State) DecodeRoot() (any, error) {
+	return deserializeState(s.state)
+}
+
+// YieldTypes returns the types of values that the coroutine yields (recv)
+// and receives back from its caller (send), that is the type parameters
+// R and S of the coroutine.Context that generated the state.
+//
+// Both types are nil if the state was not generated by
+// (*coroutine.Context).Marshal.
+func (s *State) YieldTypes() (recv, send *Type) {
+	if s.state.Root == nil {
+		return nil, nil
+	}
+	// The root region holds an interface, the first value of the scan
+	// reports the type of the object it contains.
+	scan := s.Root().Scan()
+	if !scan.Next() || scan.Kind() != reflect.Interface || scan.Nil() {
+		return nil, nil
+	}
+	t := scan.Type()
+	if t.Kind() != reflect.Pointer || t.Elem() == nil || t.Elem().Kind() != reflect.Struct {
+		return nil, nil
+	}
+	t = t.Elem()
+	if t.Package() != "github.com/stealthrocket/coroutine" || !strings.HasPrefix(t.Name(), "serializedCoroutine[") {
+		return nil, nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Type().Kind() != reflect.Array || f.Type().Len() != 0 {
+			continue
+		}
+		switch f.Name() {
+		case "recv":
+			recv = f.Type().Elem()
+		case "send":
+			send = f.Type().Elem()
+		}
+	}
+	if recv == nil || send == nil {
+		return nil, nil
+	}
+	return recv, send
+}
+
 // Type is a type referenced by a durable coroutine.
 type Type struct {
 	state *State
@@ -295,7 +388,45 @@ func (t *Type) Variadic() bool {
 	return t.typ.Variadic
 }
 
-// Opaue is true for types that had a custom serializer registered
+// Comparable is true if values of the type are comparable, and
+// therefore can be used as map keys.
+//
+// Functions, slices and maps are not comparable. Arrays are comparable
+// if their element type is, and structs are comparable if all their
+// fields are.
+func (t *Type) Comparable() bool {
+	switch t.Kind() {
+	case reflect.Func, reflect.Map, reflect.Slice:
+		return false
+	case reflect.Array:
+		return t.Elem().Comparable()
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !t.Field(i).Type().Comparable() {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+// IsError is true for the predeclared error interface type.
+func (t *Type) IsError() bool {
+	return t.typ.Kind == coroutinev1.Kind_KIND_INTERFACE && t.Name() == "error" && t.Package() == ""
+}
+
+// IsAny is true for the empty interface type (any or interface{}).
+//
+// Method sets are not recorded in the state, which means that unnamed
+// interface types with methods cannot be told apart from the empty
+// interface and are reported as any as well.
+func (t *Type) IsAny() bool {
+	return t.typ.Kind == coroutinev1.Kind_KIND_INTERFACE && t.Package() == "" && (t.Name() == "" || t.Name() == "any")
+}
+
+// Opaque is true for types that had a custom serializer registered
 // in the program that generated the coroutine state. Custom types
 // are opaque and cannot be inspected.
 func (t *Type) Opaque() bool {
@@ -376,8 +507,7 @@ func (t *Type) Format(s fmt.State, v rune) {
 		}
 		var result string
 		switch {
-		case (name == "error" && primitiveKind == "interface") ||
-			(name == "any" && primitiveKind == "interface") ||
+		case t.IsError() || (t.IsAny() && name == "any") ||
 			(name == "byte" && primitiveKind == "uint8") ||
 			(name == "rune" && primitiveKind == "int32"):
 			result = name
@@ -640,6 +770,41 @@ func (r *Region) Scan() *Scanner {
 	}
 }
 
+// PointerAt resolves the reference held by the value that starts at the
+// given byte offset in the data of the region. Values holding references
+// are pointers, unsafe pointers, slices, strings, maps, channels and
+// interfaces.
+//
+// PointerAt returns the region the value refers to and the offset into
+// that region. The region is nil if the reference is nil or points to
+// static memory. The boolean is false if no value holding a reference
+// starts at the offset, or if the region cannot be scanned.
+func (r *Region) PointerAt(offset uint64) (*Region, uint64, bool) {
+	scan := r.Scan()
+	for pos := scan.Pos(); scan.Next(); pos = scan.Pos() {
+		if uint64(pos) > offset {
+			break
+		}
+		if uint64(pos) != offset {
+			continue
+		}
+		switch scan.Kind() {
+		case reflect.Pointer, reflect.UnsafePointer, reflect.Slice, reflect.String, reflect.Interface:
+		case reflect.Map, reflect.Chan:
+			if scan.Depth() == 1 && (r.Type().Kind() == reflect.Map || r.Type().Kind() == reflect.Chan) {
+				// The region holds the contents of the map or
+				// channel rather than a reference to it.
+				continue
+			}
+		default:
+			continue
+		}
+		target, targetOffset := scan.Region()
+		return target, uint64(targetOffset), true
+	}
+	return nil, 0, false
+}
+
 // Scanner scans a Region.
 type Scanner struct {
 	state *State
@@ -651,6 +816,9 @@ type Scanner struct {
 	err   error
 	done  bool
 
+	// depth of the scan stack at which the current entity was read
+	level int
+
 	// set during iteration
 	kind     reflect.Kind
 	region   *Region
@@ -661,6 +829,7 @@ type Scanner struct {
 	nil      bool
 	len      int
 	cap      int
+	closed   bool
 	data1    uint64
 	data2    uint64
 	custom   bool
@@ -700,6 +869,7 @@ func (s *Scanner) Next() bool {
 	s.nil = false
 	s.len = 0
 	s.cap = 0
+	s.closed = false
 	s.data1 = 0
 	s.data2 = 0
 
@@ -743,6 +913,7 @@ func (s *Scanner) Next() bool {
 				last.typ = nil // only read closure struct once
 				s.kind = reflect.Struct
 				s.typ = ct
+				s.level = len(s.stack)
 				return s.readStruct(ct, 1)
 			}
 
@@ -781,6 +952,41 @@ func (s *Scanner) Depth() int {
 	return len(s.stack)
 }
 
+// mapItem is the index of the key or value of a map region entry the
+// scanner is within. Keys have even indexes, and values odd ones. It is -1
+// if the scanner is not within a map entry.
+func (s *Scanner) mapItem() int {
+	if len(s.stack) == 0 || s.stack[0].st != scanmap {
+		return -1
+	}
+	return s.stack[0].idx
+}
+
+// nested returns the depth of the scan stack at which the current entity
+// was read, and whether the entities that follow it at a greater depth are
+// nested in it (fields of a struct, elements of an array, entries of a map
+// region, etc).
+func (s *Scanner) nested() (level int, ok bool) {
+	if len(s.stack) <= s.level {
+		// Functions that are not closures do not push a step.
+		return s.level, false
+	}
+	return s.level, s.stack[len(s.stack)-1].st != scanprimitive
+}
+
+// stringValue returns the string the scanner points to.
+func (s *Scanner) stringValue() (string, bool) {
+	r, offset := s.Region()
+	if r == nil {
+		return "", true
+	}
+	data := r.region.Data
+	if int(offset)+s.Len() > len(data) {
+		return "", false
+	}
+	return string(data[offset : int(offset)+s.Len()]), true
+}
+
 // Kind is the kind of entity the scanner is pointing to.
 func (s *Scanner) Kind() reflect.Kind {
 	return s.kind
@@ -817,17 +1023,23 @@ func (s *Scanner) Nil() bool {
 	return s.nil
 }
 
-// Len is the length of the string, slice, array or map
-// the scanner is pointing to.
+// Len is the length of the string, slice, array, map or channel
+// the scanner is pointing to. The length of a channel is the number of
+// values buffered in it.
 func (s *Scanner) Len() int {
 	return s.len
 }
 
-// Cap is the capacity of the slice the scanner is pointing to.
+// Cap is the capacity of the slice or channel the scanner is pointing to.
 func (s *Scanner) Cap() int {
 	return s.cap
 }
 
+// Closed is true if the channel the scanner is pointing to is closed.
+func (s *Scanner) Closed() bool {
+	return s.closed
+}
+
 // Bool returns the bool the scanner points to.
 func (s *Scanner) Bool() bool {
 	return s.data1 == 1
@@ -920,6 +1132,7 @@ func (s *Scanner) Close() error {
 func (s *Scanner) readAny(t *Type, depth int) (ok bool) {
 	s.typ = t
 	s.kind = t.Kind()
+	s.level = depth
 
 	if depth == 0 && t.Kind() == reflect.Map {
 		// Map regions encode the contents of a map.
@@ -928,6 +1141,11 @@ func (s *Scanner) readAny(t *Type, depth int) (ok bool) {
 		// Handle the first case here, and the reference case below.
 		return s.readMap()
 	}
+	if depth == 0 && t.Kind() == reflect.Chan {
+		// Same as maps, channel regions encode the channel and
+		// the values buffered in it.
+		return s.readChan()
+	}
 
 	if t.Opaque() {
 		return s.readCustom()
@@ -943,8 +1161,6 @@ func (s *Scanner) readAny(t *Type, depth int) (ok bool) {
 		return s.readStruct(t, 0)
 	case reflect.Func:
 		return s.readFunc(t)
-	case reflect.Chan:
-		panic("not implemented: channels")
 	}
 
 	s.stack = append(s.stack, scanstep{st: scanprimitive})
@@ -966,7 +1182,7 @@ func (s *Scanner) readAny(t *Type, depth int) (ok bool) {
 		return s.readString()
 	case reflect.Slice:
 		return s.readSlice()
-	case reflect.Pointer, reflect.UnsafePointer, reflect.Map: // references
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Map, reflect.Chan: // references
 		return s.readRegionPointer()
 	case reflect.Interface:
 		return s.readInterface()
@@ -1137,6 +1353,37 @@ func (s *Scanner) readMap() (ok bool) {
 	return true
 }
 
+func (s *Scanner) readChan() (ok bool) {
+	c, ok := s.getVarint()
+	if !ok {
+		return false
+	}
+	s.cap = int(c)
+	if s.pos >= len(s.data) {
+		s.err = io.ErrShortBuffer
+		return false
+	}
+	s.closed = s.getBool()
+	n, ok := s.getVarint()
+	if !ok {
+		return false
+	}
+	s.len = int(n)
+
+	t := s.src.Type()
+	if len(s.stack) > 0 || t.Kind() != reflect.Chan {
+		panic("unexpected inline channel")
+	}
+
+	s.stack = append(s.stack, scanstep{
+		st:  scanarray,
+		idx: -1,
+		len: int(n),
+		typ: t,
+	})
+	return true
+}
+
 func (s *Scanner) readInterface() (ok bool) {
 	nonNil := s.getBool()
 	if !nonNil {
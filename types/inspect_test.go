@@ -0,0 +1,668 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInspectComparable(t *testing.T) {
+	type comparableStruct struct {
+		A int
+		B string
+		C [2]bool
+	}
+	type nonComparableStruct struct {
+		A int
+		B []int
+	}
+	type value struct {
+		Bool          bool
+		Int           int
+		String        string
+		Pointer       *int
+		Interface     any
+		Array         [4]int
+		ArrayOfSlices [2][]int
+		Slice         []int
+		Map           map[int]int
+		Func          func()
+		Struct        comparableStruct
+		NonComparable nonComparableStruct
+		Empty         struct{}
+	}
+
+	b, err := Serialize(&value{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var typ *Type
+	for i := 0; i < s.NumType(); i++ {
+		if typ = s.Type(i); typ.Name() == "value" {
+			break
+		}
+	}
+	if typ == nil || typ.Name() != "value" {
+		t.Fatal("type not found in serialized state")
+	}
+	expect := reflect.TypeOf(value{})
+	if typ.NumField() != expect.NumField() {
+		t.Fatalf("unexpected number of fields: got %d, expect %d", typ.NumField(), expect.NumField())
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if got, want := f.Type().Comparable(), expect.Field(i).Type.Comparable(); got != want {
+			t.Errorf("field %s: unexpected comparable: got %v, expect %v", f.Name(), got, want)
+		}
+	}
+}
+
+func TestInspectDecodeRoot(t *testing.T) {
+	type value struct {
+		A int
+		B []string
+	}
+	v := &value{A: 42, B: []string{"a", "b"}}
+
+	b, err := Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := s.DecodeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(root, v) {
+		t.Errorf("unexpected root: got %#v, expect %#v", root, v)
+	}
+
+	s.state.Build.Id = "not-" + s.state.Build.Id
+	if _, err := s.DecodeRoot(); !errors.Is(err, ErrBuildIDMismatch) {
+		t.Errorf("unexpected error: got %v, expect %v", err, ErrBuildIDMismatch)
+	}
+}
+
+func TestInspectInterfaces(t *testing.T) {
+	type custom interface{ Foo() }
+	type value struct {
+		Error     error
+		Any       any
+		Interface interface{}
+		Custom    custom
+		Reader    io.Reader
+		Int       int
+	}
+
+	b, err := Serialize(&value{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v *Type
+	for i := 0; i < s.NumType(); i++ {
+		if typ := s.Type(i); typ.Name() == "value" {
+			v = typ
+		}
+	}
+	if v == nil {
+		t.Fatal("value type not found")
+	}
+
+	for i, expect := range []struct {
+		isError bool
+		isAny   bool
+	}{
+		{isError: true},
+		{isAny: true},
+		{isAny: true},
+		{},
+		{},
+		{},
+	} {
+		f := v.Field(i)
+		if isError := f.Type().IsError(); isError != expect.isError {
+			t.Errorf("%s: unexpected IsError: got %v, expect %v", f.Name(), isError, expect.isError)
+		}
+		if isAny := f.Type().IsAny(); isAny != expect.isAny {
+			t.Errorf("%s: unexpected IsAny: got %v, expect %v", f.Name(), isAny, expect.isAny)
+		}
+	}
+}
+
+func TestInspectOpaque(t *testing.T) {
+	type opaque struct{ X int }
+	type value struct {
+		Opaque opaque
+		Int    int
+	}
+
+	Register[opaque](
+		func(s *Serializer, x *opaque) error {
+			SerializeT(s, x.X)
+			return nil
+		},
+		func(d *Deserializer, x *opaque) error {
+			DeserializeTo(d, &x.X)
+			return nil
+		})
+
+	b, err := Serialize(&value{Opaque: opaque{X: 42}, Int: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var v *Type
+	for i := 0; i < s.NumType(); i++ {
+		if typ := s.Type(i); typ.Name() == "value" {
+			v = typ
+		}
+	}
+	if v == nil {
+		t.Fatal("value type not found")
+	}
+
+	if f := v.Field(0); !f.Type().Opaque() {
+		t.Errorf("%s: expected registered type to be opaque", f.Name())
+	}
+	if f := v.Field(1); f.Type().Opaque() {
+		t.Errorf("%s: expected int type not to be opaque", f.Name())
+	}
+}
+
+func TestInspectMarshalJSON(t *testing.T) {
+	type node struct {
+		Name  string
+		Next  *node
+		Attrs map[string]int
+		Func  func(int) int
+	}
+	RegisterFunc[func(int) int]("github.com/stealthrocket/coroutine/types.identity")
+
+	v := &node{Name: "a", Attrs: map[string]int{"x": 1}, Func: identity}
+	v.Next = v
+
+	b, err := Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out jsonState
+	if err := json.Unmarshal(j, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Build.ID != s.BuildID() {
+		t.Errorf("unexpected build ID: got %q, expect %q", out.Build.ID, s.BuildID())
+	}
+	if len(out.Types) != s.NumType() {
+		t.Errorf("unexpected number of types: got %d, expect %d", len(out.Types), s.NumType())
+	}
+	if len(out.Strings) != s.NumString() {
+		t.Errorf("unexpected number of strings: got %d, expect %d", len(out.Strings), s.NumString())
+	}
+	if len(out.Regions) != s.NumRegion() {
+		t.Errorf("unexpected number of regions: got %d, expect %d", len(out.Regions), s.NumRegion())
+	}
+	if out.Root == nil || out.Root.Index != -1 || out.Root.Type == nil {
+		t.Errorf("unexpected root region: %+v", out.Root)
+	}
+
+	var found bool
+	for _, f := range out.Functions {
+		if f.Name == "github.com/stealthrocket/coroutine/types.identity" {
+			found = true
+			if f.Type == nil || f.Type.Name != "func(int) int" {
+				t.Errorf("unexpected function type: %+v", f.Type)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("function not found in %+v", out.Functions)
+	}
+
+	found = false
+	for _, typ := range out.Types {
+		if typ.Name != "node" {
+			continue
+		}
+		found = true
+		if typ.Kind != "struct" || len(typ.Fields) != 4 {
+			t.Fatalf("unexpected node type: %+v", typ)
+		}
+		if f := typ.Fields[1]; f.Name != "Next" || f.Type == nil || out.Types[f.Type.Index].Kind != "ptr" {
+			t.Errorf("unexpected Next field: %+v", f)
+		}
+		if f := typ.Fields[2]; f.Type == nil || out.Types[f.Type.Index].Key == nil {
+			t.Errorf("unexpected Attrs field: %+v", f)
+		}
+	}
+	if !found {
+		t.Error("node type not found")
+	}
+
+	// The root holds an interface referring to the pointer to the node,
+	// and the node points back at its own region instead of being
+	// expanded again.
+	region := func(ref any) (int, any) {
+		ptr, ok := ref.(map[string]any)
+		if !ok || ptr["offset"] != 0.0 {
+			t.Fatalf("unexpected pointer: %#v", ref)
+		}
+		index, _ := ptr["region"].(float64)
+		if index < 0 || int(index) >= len(out.Regions) {
+			t.Fatalf("invalid region index: %v", ptr["region"])
+		}
+		return int(index), out.Regions[int(index)].Value
+	}
+	iface, _ := out.Root.Value.(map[string]any)
+	_, ref := region(iface["value"])
+	index, value := region(ref)
+	n, ok := value.(map[string]any)
+	if !ok {
+		t.Fatalf("unexpected node value: %#v", value)
+	}
+	if n["Name"] != "a" || n["Func"] != "github.com/stealthrocket/coroutine/types.identity" {
+		t.Errorf("unexpected node value: %#v", n)
+	}
+	if next, _ := region(n["Next"]); next != index {
+		t.Errorf("Next is not a pointer to region %d: %#v", index, n["Next"])
+	}
+
+	_, value = region(n["Attrs"])
+	entries, ok := value.([]any)
+	if !ok || len(entries) != 1 {
+		t.Fatalf("unexpected map value: %#v", value)
+	}
+	if e, ok := entries[0].(map[string]any); !ok || e["key"] != "x" || e["value"] != 1.0 {
+		t.Errorf("unexpected map entry: %#v", entries[0])
+	}
+}
+
+func TestInspectWriteDOT(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	a := &node{Name: "a"}
+	b := &node{Name: "b", Next: a}
+	a.Next = b
+
+	buf, err := Serialize(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := s.WriteDOT(&out); err != nil {
+		t.Fatal(err)
+	}
+	dot := out.String()
+
+	if !strings.HasPrefix(dot, "digraph coroutine {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("unexpected graph:\n%s", dot)
+	}
+	if !strings.Contains(dot, "\troot [label=") || !strings.Contains(dot, "style=bold") {
+		t.Errorf("root region is not highlighted:\n%s", dot)
+	}
+	for i := 0; i < s.NumRegion(); i++ {
+		if node := fmt.Sprintf("\tr%d [label=", i); !strings.Contains(dot, node) {
+			t.Errorf("region %d not found:\n%s", i, dot)
+		}
+	}
+	// The two nodes point at each other, and the root points at the
+	// first one through its pointer.
+	for _, edge := range []string{
+		"\troot -> r0;\n",
+		"\tr1 -> r3 [label=\"Next\"];\n",
+		"\tr3 -> r1 [label=\"Next\"];\n",
+	} {
+		if !strings.Contains(dot, edge) {
+			t.Errorf("edge %q not found:\n%s", edge, dot)
+		}
+	}
+}
+
+func TestInspectDiff(t *testing.T) {
+	type node struct {
+		Name  string
+		Next  *node
+		Attrs map[string]int
+	}
+	newList := func(second string) *node {
+		b := &node{Name: second}
+		a := &node{Name: "a", Next: b, Attrs: map[string]int{}}
+		for i := 0; i < 10; i++ {
+			a.Attrs[strconv.Itoa(i)] = i
+		}
+		return a
+	}
+	inspect := func(v any) *State {
+		b, err := Serialize(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := Inspect(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	t.Run("equal", func(t *testing.T) {
+		d, err := Diff(inspect(newList("b")), inspect(newList("b")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !d.Empty() {
+			t.Errorf("unexpected differences:\n%s", d)
+		}
+	})
+
+	t.Run("regions", func(t *testing.T) {
+		d, err := Diff(inspect(newList("b")), inspect(newList("c")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d.Build) != 0 || len(d.Types) != 0 || len(d.Functions) != 0 {
+			t.Errorf("unexpected differences:\n%s", d)
+		}
+		if len(d.Regions) != 1 || d.Regions[0].A == nil || d.Regions[0].B == nil {
+			t.Fatalf("unexpected region differences:\n%s", d)
+		}
+		if path := d.Regions[0].Path; !strings.HasSuffix(path, ".Next") {
+			t.Errorf("unexpected region path: %s", path)
+		}
+	})
+
+	t.Run("types", func(t *testing.T) {
+		d, err := Diff(inspect(newList("b")), inspect(42))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var onlyInA bool
+		for _, typ := range d.Types {
+			if strings.HasSuffix(typ.Name, ".node") && typ.B == "" {
+				onlyInA = true
+			}
+		}
+		if !onlyInA {
+			t.Errorf("node type not reported as only in a:\n%s", d)
+		}
+	})
+
+	t.Run("map entries", func(t *testing.T) {
+		RegisterClosure[func() int, struct {
+			F uintptr
+			N int
+		}]("github.com/stealthrocket/coroutine/types.diffClosure.func1")
+
+		type point struct{ X, Y int }
+		type maps struct {
+			Funcs  map[string]func() int
+			Points map[point]point
+		}
+		newMaps := func(last int) *maps {
+			m := &maps{Funcs: map[string]func() int{}, Points: map[point]point{}}
+			for i := 0; i < 10; i++ {
+				n := i
+				if i == 9 {
+					n = last
+				}
+				if i%3 == 1 {
+					m.Funcs[strconv.Itoa(i)] = nil
+				} else {
+					m.Funcs[strconv.Itoa(i)] = diffClosure(n)
+				}
+				m.Points[point{i, -i}] = point{n, n * 2}
+			}
+			return m
+		}
+
+		// Map iteration order differs between the two states, entries
+		// must still be paired with the right keys.
+		for i := 0; i < 10; i++ {
+			d, err := Diff(inspect(newMaps(9)), inspect(newMaps(9)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !d.Empty() {
+				t.Fatalf("unexpected differences:\n%s", d)
+			}
+		}
+
+		d, err := Diff(inspect(newMaps(9)), inspect(newMaps(42)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var paths []string
+		for _, r := range d.Regions {
+			paths = append(paths, r.Path)
+		}
+		sort.Strings(paths)
+		if len(paths) != 2 || !strings.HasSuffix(paths[0], ".Funcs") || !strings.HasSuffix(paths[1], ".Points") {
+			t.Errorf("unexpected region differences:\n%s", d)
+		}
+	})
+
+	t.Run("build", func(t *testing.T) {
+		a, b := inspect(1), inspect(1)
+		b.state.Build.Id = "not-" + b.state.Build.Id
+		d, err := Diff(a, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(d.Build) != 1 || d.Build[0].Name != "id" {
+			t.Errorf("unexpected build differences:\n%s", d)
+		}
+		if !strings.Contains(d.String(), "build id: ") {
+			t.Errorf("build ID mismatch not printed:\n%s", d)
+		}
+	})
+}
+
+// diffClosure is not inlined so that the closure it returns has a single
+// name to register.
+//
+//go:noinline
+func diffClosure(n int) func() int {
+	return func() int { return n }
+}
+
+func TestInspectStats(t *testing.T) {
+	type value struct {
+		Name  string
+		Data  []int64
+		Ptr   *int
+		Time  time.Time
+		Attrs map[string]int
+	}
+	i := 42
+	v := &value{
+		Name:  "stats",
+		Data:  make([]int64, 100),
+		Ptr:   &i,
+		Time:  time.Now(),
+		Attrs: map[string]int{"a": 1},
+	}
+
+	b, err := Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := s.Stats()
+
+	if stats.Regions != s.NumRegion() {
+		t.Errorf("unexpected number of regions: got %d, expect %d", stats.Regions, s.NumRegion())
+	}
+	var regions int
+	var size int64
+	for _, k := range stats.Kinds {
+		regions += k.Regions
+		size += k.Bytes
+	}
+	if regions != stats.Regions || size != stats.RegionBytes {
+		t.Errorf("kinds do not add up: %d regions and %d bytes, expect %d and %d", regions, size, stats.Regions, stats.RegionBytes)
+	}
+	for _, kind := range []reflect.Kind{reflect.Array, reflect.Map, reflect.Struct, reflect.Int} {
+		if stats.Kinds[kind].Regions == 0 {
+			t.Errorf("no %s regions found: %+v", kind, stats.Kinds)
+		}
+	}
+	if stats.OpaqueTypes != 1 {
+		t.Errorf("unexpected number of opaque types: got %d, expect 1", stats.OpaqueTypes)
+	}
+	if stats.Largest == nil || stats.Largest.Type().Kind() != reflect.Array || stats.Largest.Type().Elem().Kind() != reflect.Int64 {
+		t.Errorf("unexpected largest region: %v", stats.Largest)
+	}
+}
+
+func TestInspectPointerAt(t *testing.T) {
+	type value struct {
+		X   [2]int
+		P   *int
+		Nil *int
+	}
+	v := &value{X: [2]int{1, 2}}
+	v.P = &v.X[1]
+
+	b, err := Serialize(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r *Region
+	for i := 0; i < s.NumRegion(); i++ {
+		if region := s.Region(i); region.Type().Name() == "value" {
+			r = region
+		}
+	}
+	if r == nil {
+		t.Fatal("value region not found")
+	}
+
+	// X is encoded as two 64-bit integers, followed by P.
+	target, offset, ok := r.PointerAt(16)
+	if !ok {
+		t.Fatal("pointer not found at offset 16")
+	}
+	if target == nil || target.Index() != r.Index() || offset != 8 {
+		t.Errorf("unexpected target: got %v+%d, expect %v+8", target, offset, r)
+	}
+
+	// P is encoded as two single byte varints, followed by Nil.
+	target, offset, ok = r.PointerAt(18)
+	if !ok || target != nil || offset != 0 {
+		t.Errorf("unexpected nil pointer resolution: %v+%d, %v", target, offset, ok)
+	}
+
+	if _, _, ok := r.PointerAt(0); ok {
+		t.Error("unexpected pointer at offset 0")
+	}
+	if _, _, ok := r.PointerAt(uint64(r.Size())); ok {
+		t.Error("unexpected pointer past the end of the region")
+	}
+}
+
+func TestInspectWalk(t *testing.T) {
+	type value struct {
+		A []int
+		B *string
+	}
+	str := "hello"
+	b, err := Serialize(&value{A: []int{1, 2, 3}, B: &str})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var types []int
+	s.WalkTypes(func(typ *Type) bool {
+		types = append(types, typ.Index())
+		return true
+	})
+	if len(types) != s.NumType() {
+		t.Errorf("unexpected number of types walked: got %d, expect %d", len(types), s.NumType())
+	}
+	for i, index := range types {
+		if i != index {
+			t.Errorf("unexpected type order: %v", types)
+			break
+		}
+	}
+
+	var size int64
+	var regions int
+	s.WalkRegions(func(r *Region) bool {
+		size += r.Size()
+		regions++
+		return true
+	})
+	if stats := s.Stats(); regions != stats.Regions || size != stats.RegionBytes {
+		t.Errorf("unexpected regions walked: got %d regions and %d bytes, expect %d and %d", regions, size, stats.Regions, stats.RegionBytes)
+	}
+
+	regions = 0
+	s.WalkRegions(func(r *Region) bool {
+		regions++
+		return false
+	})
+	if regions != 1 {
+		t.Errorf("walk did not stop early: %d regions walked", regions)
+	}
+
+	if typ := s.FindType("github.com/stealthrocket/coroutine/types", "value"); typ == nil || typ.Kind() != reflect.Struct {
+		t.Errorf("unexpected value type: %v", typ)
+	}
+	if typ := s.FindType("", "string"); typ == nil || typ.Kind() != reflect.String {
+		t.Errorf("unexpected string type: %v", typ)
+	}
+	if typ := s.FindType("github.com/stealthrocket/coroutine/types", "missing"); typ != nil {
+		t.Errorf("unexpected type found: %v", typ)
+	}
+}
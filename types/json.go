@@ -0,0 +1,458 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// MarshalJSON implements json.Marshaler.
+//
+// The state is encoded as an object holding the build information, the
+// string table, and the types, functions and memory regions of the state.
+// References between those items, which are stored as 1-based indexes in
+// the serialized state, are resolved into objects holding the 0-based
+// index of the referenced type and its name in Go syntax.
+//
+// The contents of regions are decoded into nested values: struct fields
+// by name, array elements, and map entries as key/value objects. Pointers
+// between regions are not followed but encoded as {"region": <index>,
+// "offset": <offset>}, so that cyclic data structures show up as an index
+// pointing back at a region.
+func (s *State) MarshalJSON() ([]byte, error) {
+	state := jsonState{
+		Build: jsonBuild{
+			ID:        s.BuildID(),
+			OS:        s.OS(),
+			Arch:      s.Arch(),
+			GoVersion: s.GoVersion(),
+		},
+		Types:     make([]jsonType, s.NumType()),
+		Functions: make([]jsonFunction, s.NumFunction()),
+		Strings:   make([]string, s.NumString()),
+		Regions:   make([]jsonRegion, s.NumRegion()),
+	}
+	for i := range state.Types {
+		state.Types[i] = newJSONType(s.Type(i))
+	}
+	for i := range state.Functions {
+		state.Functions[i] = newJSONFunction(s.Function(i))
+	}
+	for i := range state.Strings {
+		state.Strings[i] = s.String(i)
+	}
+	for i := range state.Regions {
+		r, err := newJSONRegion(s.Region(i))
+		if err != nil {
+			return nil, err
+		}
+		state.Regions[i] = r
+	}
+	if s.state.Root != nil {
+		root, err := newJSONRegion(s.Root())
+		if err != nil {
+			return nil, err
+		}
+		state.Root = &root
+	}
+	return json.Marshal(state)
+}
+
+type jsonState struct {
+	Build     jsonBuild      `json:"build"`
+	Types     []jsonType     `json:"types"`
+	Functions []jsonFunction `json:"functions"`
+	Strings   []string       `json:"strings"`
+	Regions   []jsonRegion   `json:"regions"`
+	Root      *jsonRegion    `json:"root,omitempty"`
+}
+
+type jsonBuild struct {
+	ID        string `json:"id"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	GoVersion string `json:"goVersion,omitempty"`
+}
+
+// jsonTypeRef is a reference to a type. The index is -1 for types that
+// are derived from a serialized type, such as the array type of a region.
+type jsonTypeRef struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+}
+
+func newJSONTypeRef(t *Type) *jsonTypeRef {
+	if t == nil {
+		return nil
+	}
+	return &jsonTypeRef{Index: t.Index(), Name: fmt.Sprint(t)}
+}
+
+type jsonType struct {
+	Index        int            `json:"index"`
+	Name         string         `json:"name,omitempty"`
+	Package      string         `json:"package,omitempty"`
+	Kind         string         `json:"kind"`
+	Elem         *jsonTypeRef   `json:"elem,omitempty"`
+	Key          *jsonTypeRef   `json:"key,omitempty"`
+	Len          *int           `json:"len,omitempty"`
+	ChanDir      string         `json:"chanDir,omitempty"`
+	Fields       []jsonField    `json:"fields,omitempty"`
+	Params       []*jsonTypeRef `json:"params,omitempty"`
+	Results      []*jsonTypeRef `json:"results,omitempty"`
+	Variadic     bool           `json:"variadic,omitempty"`
+	Opaque       bool           `json:"opaque,omitempty"`
+	MemoryOffset uint64         `json:"memoryOffset,omitempty"`
+}
+
+func newJSONType(t *Type) jsonType {
+	jt := jsonType{
+		Index:        t.Index(),
+		Name:         t.Name(),
+		Package:      t.Package(),
+		Kind:         t.Kind().String(),
+		Elem:         newJSONTypeRef(t.Elem()),
+		Key:          newJSONTypeRef(t.Key()),
+		Variadic:     t.Variadic(),
+		Opaque:       t.Opaque(),
+		MemoryOffset: t.MemoryOffset(),
+	}
+	switch t.Kind() {
+	case reflect.Array:
+		n := t.Len()
+		jt.Len = &n
+	case reflect.Chan:
+		jt.ChanDir = t.ChanDir().String()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		jt.Fields = append(jt.Fields, jsonField{
+			Name:      f.Name(),
+			Package:   f.Package(),
+			Type:      newJSONTypeRef(f.Type()),
+			Offset:    f.Offset(),
+			Anonymous: f.Anonymous(),
+			Tag:       string(f.Tag()),
+		})
+	}
+	for i := 0; i < t.NumParam(); i++ {
+		jt.Params = append(jt.Params, newJSONTypeRef(t.Param(i)))
+	}
+	for i := 0; i < t.NumResult(); i++ {
+		jt.Results = append(jt.Results, newJSONTypeRef(t.Result(i)))
+	}
+	return jt
+}
+
+type jsonField struct {
+	Name      string       `json:"name"`
+	Package   string       `json:"package,omitempty"`
+	Type      *jsonTypeRef `json:"type"`
+	Offset    uint64       `json:"offset"`
+	Anonymous bool         `json:"anonymous,omitempty"`
+	Tag       string       `json:"tag,omitempty"`
+}
+
+type jsonFunction struct {
+	Index   int          `json:"index"`
+	Name    string       `json:"name"`
+	Type    *jsonTypeRef `json:"type,omitempty"`
+	Closure *jsonTypeRef `json:"closure,omitempty"`
+}
+
+func newJSONFunction(f *Function) jsonFunction {
+	jf := jsonFunction{
+		Index:   f.Index(),
+		Name:    f.Name(),
+		Closure: newJSONTypeRef(f.ClosureType()),
+	}
+	if f.function.Type != 0 {
+		jf.Type = newJSONTypeRef(f.Type())
+	}
+	return jf
+}
+
+// jsonRegion is a memory region. The index is -1 for the root region.
+type jsonRegion struct {
+	Index int          `json:"index"`
+	Type  *jsonTypeRef `json:"type"`
+	Size  int64        `json:"size"`
+	Value any          `json:"value"`
+}
+
+func newJSONRegion(r *Region) (jsonRegion, error) {
+	v, err := jsonRegionValue(r)
+	if err != nil {
+		return jsonRegion{}, fmt.Errorf("scanning region %d: %w", r.Index(), err)
+	}
+	return jsonRegion{
+		Index: r.Index(),
+		Type:  newJSONTypeRef(r.Type()),
+		Size:  r.Size(),
+		Value: v,
+	}, nil
+}
+
+// jsonRegionValue decodes the contents of a region. Scalars and strings
+// are decoded, structs and closures are objects with a member per field,
+// arrays are arrays, and map entries are objects holding a key and a
+// value. Pointers, slices, maps, channels and interfaces are not followed:
+// they refer to the region they point to by index and offset, so cycles
+// end at an index.
+func jsonRegionValue(r *Region) (any, error) {
+	type open struct {
+		level int
+		value jsonContainer
+	}
+	values := jsonArray{}
+	stack := []open{{level: 0, value: &values}}
+
+	scan := r.Scan()
+	for scan.Next() {
+		level, nested := scan.nested()
+		for len(stack) > 1 && stack[len(stack)-1].level > level {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].value
+
+		var v any
+		if nested {
+			c := newJSONContainer(scan)
+			stack = append(stack, open{level: level + 1, value: c})
+			v = c
+		} else {
+			v = newJSONScalar(scan)
+		}
+		var name string
+		if f := scan.Field(); f != nil {
+			name = f.Name()
+		}
+		parent.add(name, v)
+	}
+	if err := scan.Close(); err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("region holds %d values", len(values))
+	}
+	return values[0], nil
+}
+
+// jsonContainer is a value holding the values nested in it.
+type jsonContainer interface {
+	// add adds a nested value. The name is the name of the struct field
+	// the value was read from, or empty if it was not read from a field.
+	add(name string, v any)
+}
+
+func newJSONContainer(s *Scanner) jsonContainer {
+	switch t := s.Type(); {
+	case t.Opaque():
+		return &jsonCustom{Type: newJSONTypeRef(t), Values: jsonArray{}}
+	case s.Depth() == 1 && s.Kind() == reflect.Map:
+		return &jsonMap{Entries: []*jsonMapEntry{}}
+	case s.Depth() == 1 && s.Kind() == reflect.Chan:
+		return &jsonChan{Cap: s.Cap(), Len: s.Len(), Closed: s.Closed(), Buffer: jsonArray{}}
+	case s.Kind() == reflect.Func:
+		return &jsonClosure{Function: s.Function().Name()}
+	case s.Kind() == reflect.Array:
+		return &jsonArray{}
+	default:
+		return &jsonObject{}
+	}
+}
+
+// jsonObject is a struct, with members in the order of its fields.
+type jsonObject struct {
+	names  []string
+	values []any
+}
+
+func (o *jsonObject) add(name string, v any) {
+	o.names = append(o.names, name)
+	o.values = append(o.values, v)
+}
+
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	b := []byte{'{'}
+	for i, name := range o.names {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		k, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, k...)
+		b = append(b, ':')
+		b = append(b, v...)
+	}
+	return append(b, '}'), nil
+}
+
+type jsonArray []any
+
+func (a *jsonArray) add(_ string, v any) {
+	*a = append(*a, v)
+}
+
+type jsonMap struct {
+	Entries []*jsonMapEntry
+	key     bool // the last entry is missing its value
+}
+
+type jsonMapEntry struct {
+	Key   any `json:"key"`
+	Value any `json:"value"`
+}
+
+// add adds the keys and values of the map, which are scanned in turn.
+func (m *jsonMap) add(_ string, v any) {
+	if m.key {
+		m.Entries[len(m.Entries)-1].Value = v
+	} else {
+		m.Entries = append(m.Entries, &jsonMapEntry{Key: v})
+	}
+	m.key = !m.key
+}
+
+func (m *jsonMap) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Entries)
+}
+
+type jsonChan struct {
+	Cap    int       `json:"cap"`
+	Len    int       `json:"len"`
+	Closed bool      `json:"closed"`
+	Buffer jsonArray `json:"buffer"`
+}
+
+func (c *jsonChan) add(_ string, v any) {
+	c.Buffer = append(c.Buffer, v)
+}
+
+type jsonClosure struct {
+	Function string      `json:"function"`
+	Closure  *jsonObject `json:"closure"`
+}
+
+func (c *jsonClosure) add(_ string, v any) {
+	c.Closure, _ = v.(*jsonObject)
+}
+
+// jsonCustom is a value of a type with a custom serializer, made of the
+// values that the serializer wrote.
+type jsonCustom struct {
+	Type   *jsonTypeRef `json:"type"`
+	Values jsonArray    `json:"values"`
+}
+
+func (c *jsonCustom) add(_ string, v any) {
+	c.Values = append(c.Values, v)
+}
+
+// jsonPointer is a reference to an offset in a region. Static is set
+// instead for references to static memory, which is not serialized.
+type jsonPointer struct {
+	Region int   `json:"region"`
+	Offset int64 `json:"offset"`
+}
+
+type jsonStatic struct {
+	Static bool `json:"static"`
+}
+
+type jsonSlice struct {
+	Len  int `json:"len"`
+	Cap  int `json:"cap"`
+	Data any `json:"data"`
+}
+
+type jsonInterface struct {
+	Type  *jsonTypeRef `json:"type"`
+	Value any          `json:"value"`
+}
+
+func newJSONScalar(s *Scanner) any {
+	switch s.Kind() {
+	case reflect.Bool:
+		return s.Bool()
+	case reflect.Int:
+		return s.Int()
+	case reflect.Int8:
+		return s.Int8()
+	case reflect.Int16:
+		return s.Int16()
+	case reflect.Int32:
+		return s.Int32()
+	case reflect.Int64:
+		return s.Int64()
+	case reflect.Uint:
+		return s.Uint()
+	case reflect.Uint8:
+		return s.Uint8()
+	case reflect.Uint16:
+		return s.Uint16()
+	case reflect.Uint32:
+		return s.Uint32()
+	case reflect.Uint64:
+		return s.Uint64()
+	case reflect.Uintptr:
+		return s.Uintptr()
+	case reflect.Float32:
+		return jsonFloat(float64(s.Float32()))
+	case reflect.Float64:
+		return jsonFloat(s.Float64())
+	case reflect.Complex64:
+		return fmt.Sprint(s.Complex64())
+	case reflect.Complex128:
+		return fmt.Sprint(s.Complex128())
+	case reflect.String:
+		if v, ok := s.stringValue(); ok {
+			return v
+		}
+		return fmt.Sprintf("<invalid string of length %d>", s.Len())
+	case reflect.Func:
+		if s.Nil() {
+			return nil
+		}
+		return s.Function().Name()
+	case reflect.Slice:
+		if s.Nil() {
+			return nil
+		}
+		return jsonSlice{Len: s.Len(), Cap: s.Cap(), Data: newJSONPointer(s)}
+	case reflect.Interface:
+		if s.Nil() {
+			return nil
+		}
+		return jsonInterface{Type: newJSONTypeRef(s.Type()), Value: newJSONPointer(s)}
+	default:
+		return newJSONPointer(s)
+	}
+}
+
+func newJSONPointer(s *Scanner) any {
+	switch r, offset := s.Region(); {
+	case s.Nil():
+		return nil
+	case r == nil:
+		return jsonStatic{Static: true}
+	default:
+		return jsonPointer{Region: r.Index(), Offset: offset}
+	}
+}
+
+// jsonFloat returns f, or its representation in Go syntax if it is not a
+// finite number, which encoding/json does not support.
+func jsonFloat(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Sprint(f)
+	}
+	return f
+}
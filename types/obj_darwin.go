@@ -3,12 +3,11 @@ package types
 import (
 	"bytes"
 	"debug/macho"
-	"os"
 	"strconv"
 )
 
 func init() {
-	f, err := macho.Open(os.Args[0])
+	f, err := macho.Open(executable())
 	if err != nil {
 		panic("cannot read Mach-O binary: " + err.Error())
 	}
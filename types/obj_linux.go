@@ -3,11 +3,10 @@ package types
 import (
 	"bytes"
 	"debug/elf"
-	"os"
 )
 
 func init() {
-	f, err := elf.Open(os.Args[0])
+	f, err := elf.Open(executable())
 	if err != nil {
 		panic("cannot read elf binary: " + err.Error())
 	}
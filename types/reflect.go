@@ -100,7 +100,8 @@ func serializeAny(s *Serializer, t reflect.Type, p unsafe.Pointer) {
 		serializeStruct(s, t, p)
 	case reflect.Func:
 		serializeFunc(s, t, p)
-	// Chan
+	case reflect.Chan:
+		serializeChan(s, t, p)
 	default:
 		panic(fmt.Errorf("reflection cannot serialize type %s", t))
 	}
@@ -182,6 +183,8 @@ func deserializeAny(d *Deserializer, t reflect.Type, p unsafe.Pointer) {
 		deserializeStruct(d, t, p)
 	case reflect.Func:
 		deserializeFunc(d, t, p)
+	case reflect.Chan:
+		deserializeChan(d, t, p)
 	default:
 		panic(fmt.Errorf("reflection cannot deserialize type %s", t))
 	}
@@ -245,17 +248,16 @@ func serializeReflectValue(s *Serializer, t reflect.Type, v reflect.Value) {
 			serializeReflectValue(s, f.Type, v.Field(i))
 		}
 	case reflect.Func:
-		if addr := v.Pointer(); addr != 0 {
-			if fn := FuncByAddr(addr); fn != nil && fn.Closure != nil {
-				panic("not implemented: serializing reflect.Value(closure)")
-			}
-			indirect := unsafe.Pointer(&addr)
-			serializeFunc(s, t, unsafe.Pointer(&indirect))
-		} else {
-			serializeFunc(s, t, unsafe.Pointer(&addr))
-		}
+		// Copy the func value to an addressable location so that the
+		// closure pointer (and not only the code pointer returned by
+		// v.Pointer()) is available to serializeFunc.
+		fv := reflect.New(t).Elem()
+		fv.Set(v)
+		serializeFunc(s, t, unsafe.Pointer(fv.UnsafeAddr()))
 	case reflect.Pointer:
 		serializePointedAt(s, t.Elem(), -1, v.UnsafePointer())
+	case reflect.Chan:
+		serializeChanReflect(s, t, v)
 	default:
 		panic(fmt.Sprintf("not implemented: serializing reflect.Value with type %s (%s)", t, t.Kind()))
 	}
@@ -348,17 +350,15 @@ func deserializeReflectValue(d *Deserializer, t reflect.Type) (v reflect.Value)
 			v.Field(i).Set(fv)
 		}
 	case reflect.Func:
-		var fn *Func
-		deserializeFunc(d, t, unsafe.Pointer(&fn))
 		v = reflect.New(t).Elem()
-		if fn != nil {
-			p := unsafe.Pointer(v.UnsafeAddr())
-			*(*unsafe.Pointer)(p) = unsafe.Pointer(&fn.Addr)
-		}
+		deserializeFunc(d, t, unsafe.Pointer(v.UnsafeAddr()))
 	case reflect.Pointer:
 		ep := deserializePointedAt(d, t.Elem(), -1)
 		v = reflect.New(t).Elem()
 		v.Set(reflect.NewAt(t.Elem(), ep))
+	case reflect.Chan:
+		v = reflect.New(t).Elem()
+		deserializeChanReflect(d, t, v, unsafe.Pointer(v.UnsafeAddr()))
 	default:
 		panic(fmt.Sprintf("not implemented: deserializing reflect.Value with type %s", t))
 	}
@@ -403,6 +403,10 @@ func serializePointedAt(s *Serializer, et reflect.Type, length int, p unsafe.Poi
 		serializeMap(s, r.typ, r.addr)
 		return
 	}
+	if r.len < 0 && r.typ.Kind() == reflect.Chan {
+		serializeChan(s, r.typ, r.addr)
+		return
+	}
 
 	id, new := s.assignPointerID(r.addr)
 	serializeVarint(s, int(id))
@@ -449,15 +453,16 @@ func deserializePointedAt(d *Deserializer, t reflect.Type, length int) unsafe.Po
 	// instead of taking an unsafe.Pointer as an input, it returns an
 	// unsafe.Pointer to a deserialized object.
 
-	if length < 0 && t.Kind() == reflect.Map {
-		m := reflect.New(t)
-		p := m.UnsafePointer()
-		deserializeMapReflect(d, t, m.Elem(), m.UnsafePointer())
-		return p
-	}
+	isMap := length < 0 && t.Kind() == reflect.Map
+	isChan := length < 0 && t.Kind() == reflect.Chan
 
 	id := deserializeVarint(d)
 	if id == 0 {
+		if isMap || isChan {
+			// Nil map or channel, see serializeMapReflect and
+			// serializeChanReflect.
+			return reflect.New(t).UnsafePointer()
+		}
 		// Nil pointer.
 		return unsafe.Pointer(nil)
 	}
@@ -468,6 +473,24 @@ func deserializePointedAt(d *Deserializer, t reflect.Type, length int) unsafe.Po
 		return staticPointer(offset)
 	}
 
+	// Pointers to maps that are not part of a larger region are encoded
+	// as the map itself, see serializePointedAt.
+	if isMap && id <= len(d.regions) && d.regions[id-1].Type&1 == 0 {
+		if d.types.ToReflect(typeid(d.regions[id-1].Type>>1)).Kind() == reflect.Map {
+			m := reflect.New(t)
+			deserializeMapRegion(d, t, m.Elem(), m.UnsafePointer(), id)
+			return m.UnsafePointer()
+		}
+	}
+	// Same for channels, see serializeChanReflect.
+	if isChan && id <= len(d.regions) && d.regions[id-1].Type&1 == 0 {
+		if d.types.ToReflect(typeid(d.regions[id-1].Type>>1)).Kind() == reflect.Chan {
+			c := reflect.New(t)
+			deserializeChanRegion(d, t, c.Elem(), c.UnsafePointer(), id)
+			return c.UnsafePointer()
+		}
+	}
+
 	p := d.ptrs[sID(id)]
 	if p == nil {
 		// Deserialize the region.
@@ -569,6 +592,10 @@ func deserializeMapReflect(d *Deserializer, t reflect.Type, r reflect.Value, p u
 
 	_ = deserializeVarint(d) // offset
 
+	deserializeMapRegion(d, t, r, p, id)
+}
+
+func deserializeMapRegion(d *Deserializer, t reflect.Type, r reflect.Value, p unsafe.Pointer, id int) {
 	ptr := d.ptrs[sID(id)]
 	if ptr != nil {
 		existing := reflect.NewAt(t, ptr).Elem()
@@ -600,6 +627,120 @@ func deserializeMapReflect(d *Deserializer, t reflect.Type, r reflect.Value, p u
 	}
 }
 
+func serializeChan(s *Serializer, t reflect.Type, p unsafe.Pointer) {
+	r := reflect.NewAt(t, p).Elem()
+	serializeChanReflect(s, t, r)
+}
+
+func serializeChanReflect(s *Serializer, t reflect.Type, r reflect.Value) {
+	if r.IsNil() {
+		serializeVarint(s, 0)
+		return
+	}
+
+	// Channels are referenced by identity, the same way maps are, so that
+	// values sharing a channel still share it after deserialization.
+	chanptr := r.UnsafePointer()
+
+	id, new := s.assignPointerID(chanptr)
+	serializeVarint(s, int(id))
+	serializeVarint(s, 0) // offset, for compat with other region references
+
+	if !new {
+		return
+	}
+
+	region := &coroutinev1.Region{
+		Type: s.types.ToType(t) << 1,
+	}
+	s.regions = append(s.regions, region)
+
+	// The region holds the capacity of the channel, whether it is closed,
+	// and the values buffered in the channel in the order they would be
+	// received. The buffer is read directly since receiving the values
+	// would alter the channel. Neither the buffer nor whether the channel
+	// is closed can be read if the layout of channels is not supported.
+	if !hchanLayoutOK {
+		panic(errChanLayout)
+	}
+	c := (*hchan)(chanptr)
+	state := c.state()
+
+	regionSer := s.fork()
+	serializeVarint(regionSer, r.Cap())
+	serializeBool(regionSer, c.closed != 0)
+	serializeVarint(regionSer, int(state.qcount))
+	for i := 0; i < int(state.qcount); i++ {
+		serializeAny(regionSer, t.Elem(), c.elem(i))
+	}
+	if c.state() != state {
+		panic(errChanModified)
+	}
+
+	region.Data = regionSer.b
+}
+
+func deserializeChan(d *Deserializer, t reflect.Type, p unsafe.Pointer) {
+	r := reflect.NewAt(t, p).Elem()
+	deserializeChanReflect(d, t, r, p)
+}
+
+func deserializeChanReflect(d *Deserializer, t reflect.Type, r reflect.Value, p unsafe.Pointer) {
+	id := deserializeVarint(d)
+	if id == 0 {
+		r.SetZero()
+		return
+	}
+
+	_ = deserializeVarint(d) // offset
+
+	deserializeChanRegion(d, t, r, p, id)
+}
+
+func deserializeChanRegion(d *Deserializer, t reflect.Type, r reflect.Value, p unsafe.Pointer, id int) {
+	ptr := d.ptrs[sID(id)]
+	if ptr != nil {
+		existing := reflect.NewAt(t, ptr).Elem()
+		r.Set(existing)
+		return
+	}
+
+	if id > len(d.regions) {
+		panic(fmt.Sprintf("region %d not found", id))
+	}
+	region := d.regions[id-1]
+
+	regionDeser := d.fork(region.Data)
+
+	n := deserializeVarint(regionDeser)
+	if n < 0 {
+		panic("invalid channel capacity")
+	}
+	var closed bool
+	deserializeBool(regionDeser, &closed)
+	size := deserializeVarint(regionDeser)
+	if size < 0 || size > n {
+		panic("invalid channel length")
+	}
+
+	// Channels can only be created with both directions, the result is
+	// converted to the (possibly directional) type of the value.
+	c := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, t.Elem()), n)
+	r.Set(c.Convert(t))
+	d.store(sID(id), p)
+
+	// Buffered values are sent back to the channel, which does not block
+	// since it has enough capacity to hold them.
+	v := reflect.New(t.Elem()).Elem()
+	for i := 0; i < size; i++ {
+		deserializeAny(regionDeser, t.Elem(), v.Addr().UnsafePointer())
+		c.Send(v)
+	}
+	if closed {
+		c.Close()
+	}
+}
+
 func serializeSlice(s *Serializer, t reflect.Type, p unsafe.Pointer) {
 	r := reflect.NewAt(t, p).Elem()
 
@@ -657,9 +798,15 @@ func deserializePointer(d *Deserializer, t reflect.Type, p unsafe.Pointer) {
 func serializeUnsafePointer(s *Serializer, p unsafe.Pointer) {
 	if p == nil {
 		serializePointedAt(s, nil, -1, nil)
-	} else {
-		serializePointedAt(s, nil, -1, *(*unsafe.Pointer)(p))
+		return
+	}
+	x := *(*unsafe.Pointer)(p)
+	if x != nil && !static(x) && !s.containers.of(x).valid() {
+		// The type and size of the memory x points to are unknown; see
+		// ErrUnsafePointer.
+		panic(&unsafePointerError{addr: p})
 	}
+	serializePointedAt(s, nil, -1, x)
 }
 
 var unsafePointerType = reflect.TypeOf(unsafe.Pointer(nil))
@@ -674,26 +821,22 @@ func deserializeUnsafePointer(d *Deserializer, p unsafe.Pointer) {
 }
 
 func serializeStruct(s *Serializer, t reflect.Type, p unsafe.Pointer) {
-	serializeStructFields(s, p, t.NumField(), t.Field)
+	serializeStructFields(s, p, s.types.structFields(t))
 }
 
 func deserializeStruct(d *Deserializer, t reflect.Type, p unsafe.Pointer) {
-	deserializeStructFields(d, p, t.NumField(), t.Field)
+	deserializeStructFields(d, p, d.types.structFields(t))
 }
 
-func serializeStructFields(s *Serializer, p unsafe.Pointer, n int, field func(int) reflect.StructField) {
-	for i := 0; i < n; i++ {
-		ft := field(i)
-		fp := unsafe.Add(p, ft.Offset)
-		serializeAny(s, ft.Type, fp)
+func serializeStructFields(s *Serializer, p unsafe.Pointer, fields []structField) {
+	for _, f := range fields {
+		serializeAny(s, f.typ, unsafe.Add(p, f.offset))
 	}
 }
 
-func deserializeStructFields(d *Deserializer, p unsafe.Pointer, n int, field func(int) reflect.StructField) {
-	for i := 0; i < n; i++ {
-		ft := field(i)
-		fp := unsafe.Add(p, ft.Offset)
-		deserializeAny(d, ft.Type, fp)
+func deserializeStructFields(d *Deserializer, p unsafe.Pointer, fields []structField) {
+	for _, f := range fields {
+		deserializeAny(d, f.typ, unsafe.Add(p, f.offset))
 	}
 }
 
@@ -711,9 +854,7 @@ func serializeFunc(s *Serializer, t reflect.Type, p unsafe.Pointer) {
 	if closure != nil {
 		p = unsafe.Pointer(fn)
 		// Skip the first field, which is the function ptr.
-		serializeStructFields(s, p, closure.NumField()-1, func(i int) reflect.StructField {
-			return closure.Field(i + 1)
-		})
+		serializeStructFields(s, p, s.types.structFields(closure)[1:])
 	}
 }
 
@@ -739,9 +880,7 @@ func deserializeFunc(d *Deserializer, t reflect.Type, p unsafe.Pointer) {
 		closure := v.UnsafePointer()
 		*(*uintptr)(closure) = fn.Addr
 
-		deserializeStructFields(d, closure, t.NumField()-1, func(i int) reflect.StructField {
-			return t.Field(i + 1)
-		})
+		deserializeStructFields(d, closure, d.types.structFields(t)[1:])
 
 		*(*unsafe.Pointer)(p) = closure
 	} else {
@@ -235,9 +235,8 @@ func (c *containers) insert(x container) int {
 // state with necessary information for encoding. At the moment it only creates
 // the memory regions table.
 //
-// It uses s.scanptrs to track which pointers it has already visited to avoid
-// infinite loops. It does not clean it up after. I'm sure there is something
-// more useful we could do with that.
+// Values already visited are tracked by their type and address, so that
+// cycles of pointers (e.g. a doubly linked list) are only scanned once.
 func (s *Serializer) scan(t reflect.Type, p unsafe.Pointer) {
 	s.scan1(t, p, map[reflect.Value]struct{}{})
 }
@@ -313,12 +312,8 @@ func (s *Serializer) scan1(t reflect.Type, p unsafe.Pointer, seen map[reflect.Va
 		s.scan1(et, eptr, seen)
 	case reflect.Struct:
 		s.containers.add(t, -1, p)
-		n := t.NumField()
-		for i := 0; i < n; i++ {
-			f := t.Field(i)
-			ft := f.Type
-			fp := unsafe.Add(p, f.Offset)
-			s.scan1(ft, fp, seen)
+		for _, f := range s.types.structFields(t) {
+			s.scan1(f.typ, unsafe.Add(p, f.offset), seen)
 		}
 	case reflect.Pointer:
 		if r.Elem().IsNil() {
@@ -345,6 +340,10 @@ func (s *Serializer) scan1(t reflect.Type, p unsafe.Pointer, seen map[reflect.Va
 		for iter.Next() {
 			k := iter.Key()
 			kp := (*iface)(unsafe.Pointer(&k)).ptr
+			if inlined(kt) {
+				xp := kp
+				kp = unsafe.Pointer(&xp)
+			}
 			s.scan1(kt, kp, seen)
 
 			v := iter.Value()
@@ -373,9 +372,22 @@ func (s *Serializer) scan1(t reflect.Type, p unsafe.Pointer, seen map[reflect.Va
 		reflect.Complex64,
 		reflect.Complex128:
 		// nothing to do
+	case reflect.Chan:
+		c := r.Elem()
+		if c.IsNil() {
+			return
+		}
+		h := (*hchan)(c.UnsafePointer())
+		if !hchanLayoutOK {
+			// The buffer cannot be read, serializing the channel
+			// reports the error.
+			return
+		}
+		for i := 0; i < int(h.qcount); i++ {
+			s.scan1(t.Elem(), h.elem(i), seen)
+		}
 	default:
 		// TODO:
-		// Chan
 		// Func
 		// UnsafePointer
 	}
@@ -1,16 +1,24 @@
 package types
 
 // serde.go contains the reflection based serialization and deserialization
-// procedures. It does not do any type memoization, as eventually codegen should
-// be able to generate code for types. Almost nothing is optimized, as we are
-// iterating on how it works to get it right first.
+// procedures. Only the layout of struct types is memoized (see
+// typemap.structFields), as eventually codegen should be able to generate code
+// for types. Almost nothing else is optimized, as we are iterating on how it
+// works to get it right first.
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"reflect"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"unsafe"
 
 	coroutinev1 "github.com/stealthrocket/coroutine/gen/proto/go/coroutine/v1"
@@ -23,16 +31,152 @@ type sID int64
 // to deserialize objects from another build.
 var ErrBuildIDMismatch = errors.New("build ID mismatch")
 
+// ErrGoVersionMismatch is an error that occurs when a program attempts
+// to deserialize objects from a program built with another version of Go.
+//
+// The error is only reported when deserializing with [RequireGoVersion].
+var ErrGoVersionMismatch = errors.New("Go version mismatch")
+
+// ErrUnsafePointer is an error that occurs when a program attempts to
+// serialize an unsafe.Pointer to memory of unknown type and size.
+//
+// An unsafe.Pointer is serialized as a reference to the memory region it
+// points into, so that it remains valid after deserialization. This is
+// only possible if the region is static, or if it is a struct, array,
+// slice or string that is also reachable in the serialized value. Since
+// addresses are not portable, other unsafe.Pointer values are rejected
+// rather than restored as dangling pointers; the error names the path of
+// the field holding the pointer. Types holding such pointers can be
+// serialized by registering custom serialization functions (see
+// [Register]).
+//
+// Values of type uintptr are serialized as plain integers. They are not
+// treated as addresses, and are not translated on deserialization.
+var ErrUnsafePointer = errors.New("cannot serialize unsafe.Pointer to memory of unknown type and size")
+
+// unsafePointerError is raised by the serializer when it encounters an
+// unsafe.Pointer that cannot be serialized. The path to the pointer is
+// only computed when the error is returned, see valuePath.
+type unsafePointerError struct {
+	addr unsafe.Pointer // address of the unsafe.Pointer
+	path string
+}
+
+func (e *unsafePointerError) Error() string {
+	if e.path == "" {
+		return ErrUnsafePointer.Error()
+	}
+	return e.path + ": " + ErrUnsafePointer.Error()
+}
+
+func (e *unsafePointerError) Unwrap() error {
+	return ErrUnsafePointer
+}
+
+// valuePath returns the path of struct fields and indexes leading from the
+// value of type t at address p to the unsafe.Pointer at address target.
+// Map entries are not searched, since they are not addressable.
+func valuePath(t reflect.Type, p, target unsafe.Pointer, seen map[reflect.Value]struct{}) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	if p == target && t.Kind() == reflect.UnsafePointer {
+		return "", true
+	}
+	r := reflect.NewAt(t, p)
+	if _, ok := seen[r]; ok {
+		return "", false
+	}
+	seen[r] = struct{}{}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if path, ok := valuePath(f.Type, unsafe.Add(p, f.Offset), target, seen); ok {
+				return "." + f.Name + path, true
+			}
+		}
+	case reflect.Array:
+		es := t.Elem().Size()
+		for i := 0; i < t.Len(); i++ {
+			if path, ok := valuePath(t.Elem(), unsafe.Add(p, uintptr(i)*es), target, seen); ok {
+				return "[" + strconv.Itoa(i) + "]" + path, true
+			}
+		}
+	case reflect.Slice:
+		sl := (*slice)(p)
+		es := t.Elem().Size()
+		for i := 0; i < sl.len; i++ {
+			if path, ok := valuePath(t.Elem(), unsafe.Add(sl.data, uintptr(i)*es), target, seen); ok {
+				return "[" + strconv.Itoa(i) + "]" + path, true
+			}
+		}
+	case reflect.Pointer:
+		return valuePath(t.Elem(), *(*unsafe.Pointer)(p), target, seen)
+	case reflect.Interface:
+		v := r.Elem()
+		if v.IsNil() {
+			break
+		}
+		et := v.Elem().Type()
+		ep := (*iface)(p).ptr
+		if inlined(et) {
+			ep = unsafe.Pointer(&(*iface)(p).ptr)
+		}
+		return valuePath(et, ep, target, seen)
+	}
+	return "", false
+}
+
 // Information about the current build. This is attached to serialized
 // items, and checked at deserialization time to ensure compatibility.
 var buildInfo *coroutinev1.Build
 
 func init() {
 	buildInfo = &coroutinev1.Build{
-		Id:   buildID,
-		Os:   runtime.GOOS,
-		Arch: runtime.GOARCH,
+		Id:        buildID,
+		Os:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// DeserializeOption is an option that configures [Deserialize].
+type DeserializeOption func(*deserializeOptions)
+
+type deserializeOptions struct {
+	requireGoVersion bool
+}
+
+// RequireGoVersion configures Deserialize to reject state that was
+// serialized by a program built with a different minor version of Go
+// (e.g. go1.21 and go1.22), since the memory layout of values may change
+// between releases.
+func RequireGoVersion() DeserializeOption {
+	return func(o *deserializeOptions) { o.requireGoVersion = true }
+}
+
+// goMinorVersion returns the minor version of Go of a version string
+// returned by runtime.Version, e.g. go1.21 for go1.21.3. Pre-releases and
+// trailing text are stripped as well, e.g. go1.22 for go1.22rc1 or for
+// "go1.22.0 X:loopvar". Development versions are returned as is.
+func goMinorVersion(version string) string {
+	if !strings.HasPrefix(version, "go") {
+		return version
+	}
+	i := strings.IndexByte(version, '.')
+	if i < 0 {
+		return version
+	}
+	j := i + 1
+	for j < len(version) && '0' <= version[j] && version[j] <= '9' {
+		j++
 	}
+	if j == i+1 {
+		return version
+	}
+	return version[:j]
 }
 
 // Serialize x.
@@ -40,7 +184,72 @@ func init() {
 // The output of Serialize can be reconstructed back to a Go value using
 // [Deserialize].
 func Serialize(x any) ([]byte, error) {
-	s := newSerializer()
+	var b bytes.Buffer
+	if err := SerializeTo(&b, x); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// SerializeTo serializes x and writes the encoded state to w.
+//
+// The state, including the data of all its memory regions, is built in
+// memory before anything is written. Its types, functions, strings and
+// memory regions are then encoded and written one at a time, so the byte
+// buffer holding the full encoded state is not built. The output is
+// identical to the output of [Serialize], and can be read back with
+// [DeserializeFrom] or [Deserialize].
+func SerializeTo(w io.Writer, x any) error {
+	s := AcquireSerializer()
+	defer ReleaseSerializer(s)
+	return s.SerializeTo(w, x)
+}
+
+// SerializeTo serializes x and writes the encoded state to w, like the
+// [SerializeTo] function, reusing the buffers of s. The serializer is
+// reset before use, so it can be used for successive calls.
+//
+// The method must only be called on serializers returned by
+// [AcquireSerializer], not on those passed to custom serialization
+// functions.
+func (s *Serializer) SerializeTo(w io.Writer, x any) (err error) {
+	s.reset()
+
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case *unsafePointerError:
+			path, _ := valuePath(reflect.TypeOf(&x).Elem(), unsafe.Pointer(&x), r.addr, map[reflect.Value]struct{}{})
+			r.path = fmt.Sprint(reflect.TypeOf(x)) + path
+			err = r
+		default:
+			if r != errChanLayout && r != errChanModified {
+				panic(r)
+			}
+			err = r.(error)
+		}
+	}()
+
+	state := serializeState(s, x)
+	sw := stateWriter{w: w}
+	sw.writeMessage(1, state.Build)
+	for _, t := range state.Types {
+		sw.writeMessage(3, t)
+	}
+	for _, f := range state.Functions {
+		sw.writeMessage(4, f)
+	}
+	for _, r := range state.Regions {
+		sw.writeMessage(5, r)
+	}
+	sw.writeMessage(6, state.Root)
+	for _, str := range state.Strings {
+		sw.writeBytes(7, []byte(str))
+	}
+	return sw.flush()
+}
+
+func serializeState(s *Serializer, x any) *coroutinev1.State {
 	w := &x // w is *interface{}
 	wr := reflect.ValueOf(w)
 	p := wr.UnsafePointer() // *interface{}
@@ -51,7 +260,7 @@ func Serialize(x any) ([]byte, error) {
 
 	serializeAny(s, t, p)
 
-	state := &coroutinev1.State{
+	return &coroutinev1.State{
 		Build:     buildInfo,
 		Types:     s.types.types,
 		Functions: s.funcs.funcs,
@@ -62,18 +271,41 @@ func Serialize(x any) ([]byte, error) {
 			Data: s.b,
 		},
 	}
-	return state.MarshalVT()
 }
 
 // Deserialize value from b. Return left over bytes.
-func Deserialize(b []byte) (interface{}, error) {
+func Deserialize(b []byte, options ...DeserializeOption) (interface{}, error) {
+	return DeserializeFrom(bytes.NewReader(b), options...)
+}
+
+// DeserializeFrom reads a state written by [SerializeTo] or [Serialize]
+// from r until EOF, and deserializes it.
+//
+// The fields of the state are decoded as they are read, so the byte buffer
+// holding the full encoded state is not built. The decoded state, including
+// the data of all its memory regions, is held in memory until the value is
+// reconstructed.
+func DeserializeFrom(r io.Reader, options ...DeserializeOption) (interface{}, error) {
 	var state coroutinev1.State
-	if err := state.UnmarshalVT(b); err != nil {
+	if err := readState(r, &state); err != nil {
 		return nil, err
 	}
+	return deserializeState(&state, options...)
+}
+
+func deserializeState(state *coroutinev1.State, options ...DeserializeOption) (interface{}, error) {
+	var opts deserializeOptions
+	for _, option := range options {
+		option(&opts)
+	}
 	if state.Build.Id != buildInfo.Id {
 		return nil, fmt.Errorf("%w: got %v, expect %v", ErrBuildIDMismatch, state.Build.Id, buildInfo.Id)
 	}
+	if opts.requireGoVersion {
+		if v := state.Build.GoVersion; goMinorVersion(v) != goMinorVersion(buildInfo.GoVersion) {
+			return nil, fmt.Errorf("%w: got %v, expect %v", ErrGoVersionMismatch, v, buildInfo.GoVersion)
+		}
+	}
 
 	d := newDeserializer(state.Root.Data, state.Types, state.Functions, state.Regions, state.Strings)
 
@@ -89,6 +321,223 @@ func Deserialize(b []byte) (interface{}, error) {
 	return x, nil
 }
 
+// SerializeWriter serializes x and writes it to w, prefixed with the
+// length of the serialized state.
+//
+// The output of SerializeWriter can be read back with [DeserializeReader],
+// which makes it possible to embed a serialized value in a larger stream.
+func SerializeWriter(w io.Writer, x any) error {
+	b, err := Serialize(x)
+	if err != nil {
+		return err
+	}
+	var prefix [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(prefix[:], uint64(len(b)))
+	if _, err := w.Write(prefix[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// DeserializeReader reads exactly one value written by [SerializeWriter]
+// from r and deserializes it.
+//
+// The reader is left positioned right after the serialized value, so
+// that data following it can be read. The options are the same as for
+// [Deserialize].
+func DeserializeReader(r io.Reader, options ...DeserializeOption) (any, error) {
+	size, err := binary.ReadUvarint(byteReader{r})
+	if err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("reading serialized state size: %w", err)
+	}
+	if size > math.MaxInt32 {
+		return nil, fmt.Errorf("serialized state is too large: %d bytes", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("reading serialized state: %w", err)
+	}
+	return Deserialize(b, options...)
+}
+
+// maxStateField is the maximum size of a field of an encoded state.
+const maxStateField = math.MaxInt32
+
+// vtMessage is implemented by the coroutinev1 messages that make up a
+// serialized state.
+type vtMessage interface {
+	SizeVT() int
+	MarshalToSizedBufferVT([]byte) (int, error)
+	UnmarshalVT([]byte) error
+}
+
+// stateWriter writes the length-delimited fields of an encoded
+// coroutinev1.State to an io.Writer, buffering small writes.
+type stateWriter struct {
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+func (sw *stateWriter) writeTag(field, size int) {
+	sw.buf = binary.AppendUvarint(sw.buf, uint64(field)<<3|2)
+	sw.buf = binary.AppendUvarint(sw.buf, uint64(size))
+}
+
+func (sw *stateWriter) writeMessage(field int, m vtMessage) {
+	if sw.err != nil {
+		return
+	}
+	size := m.SizeVT()
+	sw.writeTag(field, size)
+	n := len(sw.buf)
+	sw.buf = append(sw.buf, make([]byte, size)...)
+	if _, err := m.MarshalToSizedBufferVT(sw.buf[n:]); err != nil {
+		sw.err = err
+		return
+	}
+	sw.maybeFlush()
+}
+
+func (sw *stateWriter) writeBytes(field int, b []byte) {
+	if sw.err != nil {
+		return
+	}
+	sw.writeTag(field, len(b))
+	sw.buf = append(sw.buf, b...)
+	sw.maybeFlush()
+}
+
+func (sw *stateWriter) maybeFlush() {
+	if len(sw.buf) >= 32*1024 {
+		sw.flush()
+	}
+}
+
+func (sw *stateWriter) flush() error {
+	if sw.err == nil && len(sw.buf) > 0 {
+		_, sw.err = sw.w.Write(sw.buf)
+		sw.buf = sw.buf[:0]
+	}
+	return sw.err
+}
+
+// readState decodes an encoded coroutinev1.State from r, one field at a
+// time. Nested messages copy the bytes they retain, so a single scratch
+// buffer is reused to read fields.
+func readState(r io.Reader, state *coroutinev1.State) error {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		b := bufio.NewReader(r)
+		br, r = b, b
+	}
+	var buf []byte
+	for {
+		tag, err := binary.ReadUvarint(br)
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("reading state field tag: %w", err)
+		}
+		field, wireType := int(tag>>3), int(tag&7)
+
+		switch wireType {
+		case 0: // varint
+			if _, err := binary.ReadUvarint(br); err != nil {
+				return fmt.Errorf("reading state field %d: %w", field, noEOF(err))
+			}
+			continue
+		case 1: // fixed64
+			if _, err := io.CopyN(io.Discard, r, 8); err != nil {
+				return fmt.Errorf("reading state field %d: %w", field, noEOF(err))
+			}
+			continue
+		case 5: // fixed32
+			if _, err := io.CopyN(io.Discard, r, 4); err != nil {
+				return fmt.Errorf("reading state field %d: %w", field, noEOF(err))
+			}
+			continue
+		case 2: // length-delimited
+		default:
+			return fmt.Errorf("state field %d has invalid wire type %d", field, wireType)
+		}
+
+		size, err := binary.ReadUvarint(br)
+		if err != nil {
+			return fmt.Errorf("reading state field %d size: %w", field, noEOF(err))
+		}
+		if size > maxStateField {
+			return fmt.Errorf("state field %d is too large: %d bytes", field, size)
+		}
+		if uint64(cap(buf)) < size {
+			buf = make([]byte, size)
+		}
+		buf = buf[:size]
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return fmt.Errorf("reading state field %d: %w", field, noEOF(err))
+		}
+
+		var m vtMessage
+		switch field {
+		case 1:
+			state.Build = new(coroutinev1.Build)
+			m = state.Build
+		case 2:
+			state.State = append(state.State[:0], buf...)
+		case 3:
+			t := new(coroutinev1.Type)
+			state.Types = append(state.Types, t)
+			m = t
+		case 4:
+			f := new(coroutinev1.Function)
+			state.Functions = append(state.Functions, f)
+			m = f
+		case 5:
+			region := new(coroutinev1.Region)
+			state.Regions = append(state.Regions, region)
+			m = region
+		case 6:
+			state.Root = new(coroutinev1.Region)
+			m = state.Root
+		case 7:
+			state.Strings = append(state.Strings, string(buf))
+		}
+		if m != nil {
+			if err := m.UnmarshalVT(buf); err != nil {
+				return fmt.Errorf("decoding state field %d: %w", field, err)
+			}
+		}
+	}
+}
+
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// byteReader reads one byte at a time from an io.Reader, to avoid reading
+// past the length prefix of a serialized value.
+type byteReader struct{ io.Reader }
+
+func (r byteReader) ReadByte() (byte, error) {
+	if br, ok := r.Reader.(io.ByteReader); ok {
+		return br.ReadByte()
+	}
+	var b [1]byte
+	_, err := io.ReadFull(r.Reader, b[:])
+	return b[0], err
+}
+
 type Deserializer struct {
 	*deserializerContext
 
@@ -174,6 +623,11 @@ type serializerContext struct {
 	ptrs       map[unsafe.Pointer]sID
 	regions    []*coroutinev1.Region
 	containers containers
+
+	// Serializers created by fork, whose buffers hold the data of
+	// regions, and serializers available for reuse by fork.
+	forks []*Serializer
+	free  []*Serializer
 }
 
 func newSerializer() *Serializer {
@@ -193,10 +647,104 @@ func newSerializer() *Serializer {
 }
 
 func (s *Serializer) fork() *Serializer {
-	return &Serializer{
-		s.serializerContext,
-		make([]byte, 0, 128),
+	var f *Serializer
+	if n := len(s.free); n > 0 {
+		f = s.free[n-1]
+		s.free = s.free[:n-1]
+	} else {
+		f = &Serializer{
+			s.serializerContext,
+			make([]byte, 0, 128),
+		}
+	}
+	s.forks = append(s.forks, f)
+	return f
+}
+
+// maxPooledBuffer is the capacity above which serializer buffers are
+// dropped instead of being reused, so that serializing one large value
+// does not pin its memory in the pool.
+const maxPooledBuffer = 1 << 20
+
+// maxPooledForks is the number of forks retained for reuse by a
+// serializer, and maxPooledRegions the capacity above which its tables of
+// pointers, regions and containers are dropped, for the same reason.
+const (
+	maxPooledForks   = 64
+	maxPooledRegions = 4096
+)
+
+var serializerPool sync.Pool
+
+// AcquireSerializer returns a serializer from a pool, or a new one if the
+// pool is empty. Its (*Serializer).SerializeTo method can be called to
+// serialize values with buffers reused across calls, which reduces the
+// allocations of programs serializing many values. The serializer must be
+// released with [ReleaseSerializer] once done.
+func AcquireSerializer() *Serializer {
+	if s, ok := serializerPool.Get().(*Serializer); ok {
+		return s
+	}
+	return newSerializer()
+}
+
+// ReleaseSerializer resets s and puts it back in the pool. The serializer
+// must not be used after it is released.
+func ReleaseSerializer(s *Serializer) {
+	s.reset()
+	serializerPool.Put(s)
+}
+
+// reset clears the state of the serializer so that no types, strings,
+// pointers or regions leak from one use to the next, and releases
+// references to the values it serialized. Buffers of the serializer and of
+// a bounded number of its forks are retained for reuse.
+//
+// The method is not exported since the serializers passed to custom
+// serialization functions must not be reset while in use.
+func (s *Serializer) reset() {
+	s.b = resetBuffer(s.b)
+	s.types.reset()
+	s.funcs.reset()
+	s.strings.reset()
+	if len(s.ptrs) > maxPooledRegions {
+		s.ptrs = make(map[unsafe.Pointer]sID)
+	} else {
+		clear(s.ptrs)
+	}
+	if cap(s.regions) > maxPooledRegions {
+		s.regions = nil
+	} else {
+		clear(s.regions)
+		s.regions = s.regions[:0]
+	}
+	if cap(s.containers) > maxPooledRegions {
+		s.containers = nil
+	} else {
+		clear(s.containers)
+		s.containers = s.containers[:0]
+	}
+
+	for _, f := range s.forks {
+		if len(s.free) == maxPooledForks {
+			break
+		}
+		f.b = resetBuffer(f.b)
+		s.free = append(s.free, f)
+	}
+	if cap(s.forks) > maxPooledForks {
+		s.forks = nil
+	} else {
+		clear(s.forks)
+		s.forks = s.forks[:0]
+	}
+}
+
+func resetBuffer(b []byte) []byte {
+	if cap(b) > maxPooledBuffer {
+		return make([]byte, 0, 128)
 	}
+	return b[:0]
 }
 
 // Returns true if it created a new ID (false if reused one).
@@ -219,7 +767,7 @@ func deserializeVarint(d *Deserializer) int {
 	return int(l)
 }
 
-// Serialize a value. See [RegisterSerde].
+// Serialize a value. See [Register].
 func SerializeT[T any](s *Serializer, x T) {
 	var p unsafe.Pointer
 	r := reflect.ValueOf(x)
@@ -235,7 +783,7 @@ func SerializeT[T any](s *Serializer, x T) {
 	serializeAny(s, t, p)
 }
 
-// Deserialize a value to the provided non-nil pointer. See [RegisterSerde].
+// Deserialize a value to the provided non-nil pointer. See [Register].
 func DeserializeTo[T any](d *Deserializer, x *T) {
 	r := reflect.ValueOf(x)
 	t := r.Type().Elem()
@@ -245,7 +793,7 @@ func DeserializeTo[T any](d *Deserializer, x *T) {
 		if t != actualType {
 			panic(fmt.Sprintf("cannot deserialize %s as %s", actualType, t))
 		}
-	} else if t.Kind() != reflect.Array || t.Len() != length || t != actualType.Elem() {
+	} else if t.Kind() != reflect.Array || t.Len() != length || t.Elem() != actualType {
 		panic(fmt.Sprintf("cannot deserialize [%d]%s as %s", length, actualType, t))
 	}
 	deserializeAny(d, t, p)
@@ -6,14 +6,20 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"math"
+	"math/big"
 	"net/http"
 	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 	"unsafe"
+
+	coroutinev1 "github.com/stealthrocket/coroutine/gen/proto/go/coroutine/v1"
 )
 
 func TestSerdeTime(t *testing.T) {
@@ -38,6 +44,49 @@ func TestSerdeTime(t *testing.T) {
 
 		testSerdeTime(t, t2)
 	})
+
+	t.Run("struct with time fields", func(t *testing.T) {
+		type event struct {
+			Name string
+			At   time.Time
+			Next *time.Time
+		}
+
+		loc, err := time.LoadLocation("Europe/Paris")
+		if err != nil {
+			t.Fatal("failed to load location", err)
+		}
+		now := time.Now()
+		next := now.Add(time.Hour).In(loc)
+		x := event{Name: "tick", At: now, Next: &next}
+
+		b, err := Serialize(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Deserialize(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assertCanInspect(t, b)
+
+		y := out.(event)
+		if y.Name != x.Name {
+			t.Errorf("expected name %q, got %q", x.Name, y.Name)
+		}
+		if !y.At.Equal(x.At) {
+			t.Errorf("expected %v, got %v", x.At, y.At)
+		}
+		if y.Next == nil || !y.Next.Equal(next) {
+			t.Fatalf("expected %v, got %v", next, y.Next)
+		}
+		// MarshalBinary only records the zone offset, not its name.
+		_, expectOffset := next.Zone()
+		if _, offset := y.Next.Zone(); offset != expectOffset {
+			t.Errorf("expected zone offset %d, got %d", expectOffset, offset)
+		}
+	})
 }
 
 func testSerdeTime(t *testing.T, x time.Time) {
@@ -247,6 +296,56 @@ func TestReflectUnsafePointer(t *testing.T) {
 	}
 }
 
+func TestReflectUnsafePointerUnknownRegion(t *testing.T) {
+	type inner struct{ p unsafe.Pointer }
+	type outer struct {
+		x     int
+		inner inner
+	}
+
+	// The pointed-at int is not reachable through a typed pointer, so its
+	// type and size are unknown to the serializer.
+	v := &outer{inner: inner{p: unsafe.Pointer(new(int))}}
+
+	_, err := Serialize(v)
+	if !errors.Is(err, ErrUnsafePointer) {
+		t.Fatalf("unexpected error: got %v, expect %v", err, ErrUnsafePointer)
+	}
+	if want := "*types.outer.inner.p: "; !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("error does not name the field path: got %q, expect prefix %q", err, want)
+	}
+
+	// Indexes of slices and arrays are part of the path.
+	type list struct {
+		items []inner
+	}
+	_, err = Serialize(list{items: []inner{{}, {p: unsafe.Pointer(new(int))}}})
+	if want := "types.list.items[1].p: "; !strings.HasPrefix(fmt.Sprint(err), want) {
+		t.Errorf("error does not name the field path: got %q, expect prefix %q", err, want)
+	}
+
+	// Pointers into structs that are also reachable through a typed
+	// pointer are serialized as references to the same region.
+	type node struct{ v int }
+	type both struct {
+		typed *node
+		p     unsafe.Pointer
+	}
+	n := &node{v: 42}
+	b, err := Serialize(&both{typed: n, p: unsafe.Pointer(&n.v)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Deserialize(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := out.(*both)
+	if unsafe.Pointer(&res.typed.v) != res.p || *(*int)(res.p) != 42 {
+		t.Errorf("unsafe.Pointer was not restored correctly")
+	}
+}
+
 func TestReflectFunc(t *testing.T) {
 	RegisterFunc[func(int) int]("github.com/stealthrocket/coroutine/types.identity")
 
@@ -296,9 +395,6 @@ func TestReflectClosure(t *testing.T) {
 	})
 
 	t.Run("reflect-value", func(t *testing.T) {
-		// FIXME: get reflect.Value(closure) working
-		t.Skipf("reflect.Value(closure) is not working correctly")
-
 		b, err := Serialize(reflect.ValueOf(fn))
 		if err != nil {
 			t.Fatal(err)
@@ -314,6 +410,27 @@ func TestReflectClosure(t *testing.T) {
 			t.Errorf("unexpected closure reflect call results: %#v", res)
 		}
 	})
+
+	t.Run("build-id-mismatch", func(t *testing.T) {
+		b, err := Serialize(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var state coroutinev1.State
+		if err := state.UnmarshalVT(b); err != nil {
+			t.Fatal(err)
+		}
+		state.Build.Id = "not-" + state.Build.Id
+		b, err = state.MarshalVT()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := Deserialize(b); !errors.Is(err, ErrBuildIDMismatch) {
+			t.Errorf("unexpected error: got %v, expect %v", err, ErrBuildIDMismatch)
+		}
+	})
 }
 
 func TestErrors(t *testing.T) {
@@ -344,6 +461,140 @@ func TestEmptyStructs(t *testing.T) {
 	assertRoundTrip(t, Y{first: 42, last: struct{}{}})
 }
 
+func TestStructPadding(t *testing.T) {
+	type padded struct {
+		A bool
+		B int64
+		C bool
+	}
+	type nested struct {
+		A uint8
+		P padded
+		B uint16
+		Q [2]padded
+		C bool
+	}
+
+	assertRoundTrip(t, padded{A: true, B: math.MaxInt64, C: true})
+	assertRoundTrip(t, padded{B: -1})
+	assertRoundTrip(t, nested{
+		A: 0xff,
+		P: padded{A: true, B: math.MinInt64, C: true},
+		B: 0xffff,
+		Q: [2]padded{{A: true, B: 1}, {B: 2, C: true}},
+		C: true,
+	})
+
+	// Pointers to fields located after padding bytes must still point
+	// to the right field after deserialization.
+	type pointers struct {
+		P  padded
+		PA *bool
+		PB *int64
+		PC *bool
+	}
+	x := &pointers{P: padded{A: true, B: 42, C: true}}
+	x.PA, x.PB, x.PC = &x.P.A, &x.P.B, &x.P.C
+	out := assertRoundTrip(t, x)
+	assertEqual(t, unsafe.Pointer(&out.P.A), unsafe.Pointer(out.PA))
+	assertEqual(t, unsafe.Pointer(&out.P.B), unsafe.Pointer(out.PB))
+	assertEqual(t, unsafe.Pointer(&out.P.C), unsafe.Pointer(out.PC))
+}
+
+func TestAnonymousStructs(t *testing.T) {
+	assertRoundTrip[any](t, struct{ X int }{X: 1})
+	assertRoundTrip[any](t, &struct{ X, Y string }{X: "x", Y: "y"})
+	assertRoundTrip[any](t, struct {
+		A struct{ B []int }
+		c *struct{ d bool }
+	}{
+		A: struct{ B []int }{B: []int{1, 2}},
+		c: &struct{ d bool }{d: true},
+	})
+
+	// Identical anonymous struct types must be decoded to the same type.
+	x := []any{struct{ X int }{X: 1}, struct{ X int }{X: 2}, struct{ X int64 }{X: 3}}
+	out := assertRoundTrip(t, x)
+	assertEqual(t, reflect.TypeOf(out[0]), reflect.TypeOf(out[1]))
+	if reflect.TypeOf(out[0]) == reflect.TypeOf(out[2]) {
+		t.Errorf("distinct anonymous struct types decoded to the same type %s", reflect.TypeOf(out[0]))
+	}
+	assertEqual(t, 2, out[1].(struct{ X int }).X)
+}
+
+func TestNilInterfaces(t *testing.T) {
+	var nilErr error
+	x := []any{nil, 0, nil, "", nil, (*int)(nil), nilErr, false, nil}
+	out := assertRoundTrip(t, x)
+	if len(out) != len(x) {
+		t.Fatalf("expected %d elements, got %d", len(x), len(out))
+	}
+	for i := range x {
+		if (x[i] == nil) != (out[i] == nil) {
+			t.Errorf("element %d: expected %#v, got %#v", i, x[i], out[i])
+		}
+	}
+	if _, ok := out[5].(*int); !ok {
+		t.Errorf("element 5: typed nil pointer decoded as %#v", out[5])
+	}
+
+	// Nil interfaces nested in other containers must also be preserved.
+	type S struct {
+		A any
+		B []error
+		C map[string]any
+	}
+	assertRoundTrip(t, S{
+		B: []error{nil, errors.New("fail"), nil},
+		C: map[string]any{"nil": nil, "zero": 0},
+	})
+}
+
+type shape interface{ Area() int }
+
+type rect struct{ W, H int }
+
+func (r rect) Area() int { return r.W * r.H }
+
+type square struct{ rect *rect }
+
+func (s *square) Area() int { return s.rect.Area() }
+
+type shapeError struct{ shape shape }
+
+func (e *shapeError) Error() string { return fmt.Sprintf("invalid shape of area %d", e.shape.Area()) }
+
+func TestInterfacesWithUnregisteredTypes(t *testing.T) {
+	r := &rect{W: 2, H: 3}
+	x := struct {
+		Any    any
+		Shapes []shape
+		Err    error
+	}{
+		Any:    EasyStruct{A: 1, B: "one"},
+		Shapes: []shape{rect{W: 4, H: 5}, &square{rect: r}, nil},
+		Err:    &shapeError{shape: &square{rect: r}},
+	}
+
+	out := assertRoundTrip(t, x)
+
+	assertEqual(t, EasyStruct{A: 1, B: "one"}, out.Any)
+	assertEqual(t, 20, out.Shapes[0].Area())
+	assertEqual(t, 6, out.Shapes[1].Area())
+	if out.Shapes[2] != nil {
+		t.Errorf("nil interface decoded as %#v", out.Shapes[2])
+	}
+	assertEqual(t, "invalid shape of area 6", out.Err.Error())
+
+	// The concrete values must be re-boxed with the original types, and
+	// pointers they hold must still be shared.
+	sq1 := out.Shapes[1].(*square)
+	sq2 := out.Err.(*shapeError).shape.(*square)
+	if sq1.rect != sq2.rect {
+		t.Error("pointer held by values in interfaces is not shared")
+	}
+}
+
 func TestInt257(t *testing.T) {
 	one := 1
 	x := []any{
@@ -353,6 +604,400 @@ func TestInt257(t *testing.T) {
 	assertRoundTrip(t, x)
 }
 
+func TestSerializeWriter(t *testing.T) {
+	type X struct {
+		A int
+		B string
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("header")
+	if err := SerializeWriter(&buf, &X{A: 1, B: "one"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SerializeWriter(&buf, []int{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("trailer")
+
+	for _, test := range []struct {
+		name string
+		r    func([]byte) io.Reader
+	}{
+		{"byte reader", func(b []byte) io.Reader { return bytes.NewReader(b) }},
+		{"reader", func(b []byte) io.Reader { return struct{ io.Reader }{bytes.NewReader(b)} }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := test.r(buf.Bytes())
+
+			header := make([]byte, len("header"))
+			if _, err := io.ReadFull(r, header); err != nil {
+				t.Fatal(err)
+			}
+			assertEqual(t, "header", string(header))
+
+			x, err := DeserializeReader(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEqual(t, &X{A: 1, B: "one"}, x)
+
+			y, err := DeserializeReader(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEqual(t, []int{1, 2, 3}, y)
+
+			trailer, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertEqual(t, "trailer", string(trailer))
+
+			if _, err := DeserializeReader(r); err != io.EOF {
+				t.Errorf("expected io.EOF, got %v", err)
+			}
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := SerializeWriter(&buf, 42); err != nil {
+			t.Fatal(err)
+		}
+		b := buf.Bytes()
+		_, err := DeserializeReader(bytes.NewReader(b[:len(b)-1]))
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+		}
+	})
+}
+
+func TestSerializeTo(t *testing.T) {
+	type X struct {
+		A []int
+		B map[string]*X
+		C string
+	}
+
+	x := &X{A: []int{1, 2, 3}, C: "root"}
+	x.B = map[string]*X{"self": x, "other": {C: "other"}}
+
+	var buf bytes.Buffer
+	if err := SerializeTo(&buf, x); err != nil {
+		t.Fatal(err)
+	}
+
+	// The streamed output must be a valid encoding of the whole state.
+	var state coroutinev1.State
+	if err := state.UnmarshalVT(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := state.MarshalVT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, buf.Bytes()) {
+		t.Error("streamed state differs from the marshaled state")
+	}
+
+	for _, test := range []struct {
+		name string
+		r    func([]byte) io.Reader
+	}{
+		{"byte reader", func(b []byte) io.Reader { return bytes.NewReader(b) }},
+		{"reader", func(b []byte) io.Reader { return struct{ io.Reader }{bytes.NewReader(b)} }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := DeserializeFrom(test.r(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			y := out.(*X)
+			assertEqual(t, x.A, y.A)
+			assertEqual(t, x.C, y.C)
+			if y.B["self"] != y {
+				t.Error("cycle was not preserved")
+			}
+			assertEqual(t, "other", y.B["other"].C)
+		})
+	}
+
+	t.Run("truncated", func(t *testing.T) {
+		b := buf.Bytes()
+		_, err := DeserializeFrom(bytes.NewReader(b[:len(b)-1]))
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+		}
+	})
+}
+
+func TestSerializerReset(t *testing.T) {
+	type X struct {
+		Name string
+		Next *X
+		Map  map[string]int
+	}
+	x := &X{Name: "a", Map: map[string]int{"b": 1}}
+	x.Next = x
+
+	s := newSerializer()
+	state := serializeState(s, x)
+	if len(state.Regions) == 0 || len(state.Strings) == 0 || len(s.ptrs) == 0 {
+		t.Fatal("expected the first state to have regions, strings and pointers")
+	}
+	s.reset()
+
+	state = serializeState(s, 42)
+	fresh := serializeState(newSerializer(), 42)
+	if n, m := len(state.Regions), len(fresh.Regions); n != m {
+		t.Errorf("regions leaked after reset: got %d, expect %d", n, m)
+	}
+	if n, m := len(state.Strings), len(fresh.Strings); n != m {
+		t.Errorf("strings leaked after reset: got %d, expect %d", n, m)
+	}
+	if n, m := len(state.Types), len(fresh.Types); n != m {
+		t.Errorf("types leaked after reset: got %d, expect %d", n, m)
+	}
+
+	b, err := state.MarshalVT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := fresh.MarshalVT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, expect) {
+		t.Error("state serialized after reset differs from a fresh serialization")
+	}
+
+	// Serialize the first value again, reusing the pooled buffers of
+	// the regions, and check that the result is still valid.
+	s.reset()
+	b, err = serializeState(s, x).MarshalVT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := Deserialize(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := out.(*X)
+	if y.Next != y || y.Name != "a" || y.Map["b"] != 1 {
+		t.Errorf("unexpected value after reset: %+v", y)
+	}
+}
+
+func TestAcquireSerializer(t *testing.T) {
+	s := AcquireSerializer()
+	defer ReleaseSerializer(s)
+
+	// Many maps create many forks, only a bounded number of them is
+	// retained after reset.
+	maps := make([]map[int]int, 2*maxPooledForks)
+	for i := range maps {
+		maps[i] = map[int]int{i: i}
+	}
+
+	for _, x := range []any{maps, 42, maps} {
+		var b bytes.Buffer
+		if err := s.SerializeTo(&b, x); err != nil {
+			t.Fatal(err)
+		}
+		expect, err := Serialize(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b.Bytes(), expect) {
+			t.Errorf("state serialized with a reused serializer differs from a fresh serialization")
+		}
+		if n := len(s.free); n > maxPooledForks {
+			t.Errorf("too many forks retained: got %d, expect at most %d", n, maxPooledForks)
+		}
+	}
+
+	s.reset()
+	if len(s.ptrs) != 0 || len(s.regions) != 0 || len(s.forks) != 0 {
+		t.Error("state of the serializer was not cleared by reset")
+	}
+}
+
+func TestRequireGoVersion(t *testing.T) {
+	b, err := Serialize(42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Deserialize(b, RequireGoVersion()); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Inspect(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := s.GoVersion(); v != runtime.Version() {
+		t.Errorf("unexpected Go version: got %q, expect %q", v, runtime.Version())
+	}
+
+	s.state.Build.GoVersion = "go1.0.1"
+	b, err = s.state.MarshalVT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Deserialize(b); err != nil {
+		t.Errorf("unexpected error without version requirement: %v", err)
+	}
+	if _, err := Deserialize(b, RequireGoVersion()); !errors.Is(err, ErrGoVersionMismatch) {
+		t.Errorf("unexpected error: got %v, expect %v", err, ErrGoVersionMismatch)
+	}
+
+	// Length-framed states accept the same options.
+	framed := bytes.NewReader(append(binary.AppendUvarint(nil, uint64(len(b))), b...))
+	if _, err := DeserializeReader(framed, RequireGoVersion()); !errors.Is(err, ErrGoVersionMismatch) {
+		t.Errorf("unexpected error reading framed state: got %v, expect %v", err, ErrGoVersionMismatch)
+	}
+	var buf bytes.Buffer
+	if err := SerializeWriter(&buf, 42); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DeserializeReader(&buf, RequireGoVersion()); err != nil {
+		t.Errorf("unexpected error reading framed state: %v", err)
+	}
+}
+
+func TestGoMinorVersion(t *testing.T) {
+	for _, test := range []struct {
+		version string
+		minor   string
+	}{
+		{"go1.21", "go1.21"},
+		{"go1.21.0", "go1.21"},
+		{"go1.21.13", "go1.21"},
+		{"go1.22rc1", "go1.22"},
+		{"go1.23beta2", "go1.23"},
+		{"go1.22.0 X:loopvar", "go1.22"},
+		{"go1.22rc1 X:rangefunc", "go1.22"},
+		{"go1.21.13 X:boringcrypto", "go1.21"},
+		{"go1", "go1"},
+		{"devel go1.22-abcdef", "devel go1.22-abcdef"},
+	} {
+		if minor := goMinorVersion(test.version); minor != test.minor {
+			t.Errorf("%s: got %q, expect %q", test.version, minor, test.minor)
+		}
+	}
+}
+
+func TestSerdeBig(t *testing.T) {
+	large, _ := new(big.Int).SetString("-123456789012345678901234567890123456789012345678901234567890", 10)
+
+	type S struct {
+		Int   *big.Int
+		Zero  big.Int
+		Rat   *big.Rat
+		Float *big.Float
+	}
+
+	in := &S{
+		Int:   large,
+		Rat:   big.NewRat(-355, 113),
+		Float: new(big.Float).SetPrec(200).Quo(big.NewFloat(-1), big.NewFloat(3)),
+	}
+
+	b, err := Serialize(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := Deserialize(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := v.(*S)
+
+	if out.Int.Cmp(in.Int) != 0 {
+		t.Errorf("unexpected big.Int: got %v, expect %v", out.Int, in.Int)
+	}
+	if out.Zero.Sign() != 0 {
+		t.Errorf("unexpected zero big.Int: got %v", &out.Zero)
+	}
+	if out.Rat.Cmp(in.Rat) != 0 {
+		t.Errorf("unexpected big.Rat: got %v, expect %v", out.Rat, in.Rat)
+	}
+	if out.Float.Cmp(in.Float) != 0 || out.Float.Prec() != in.Float.Prec() {
+		t.Errorf("unexpected big.Float: got %v (prec %d), expect %v (prec %d)",
+			out.Float, out.Float.Prec(), in.Float, in.Float.Prec())
+	}
+}
+
+func TestSerdeSync(t *testing.T) {
+	type S struct {
+		Mutex     sync.Mutex
+		Once      sync.Once
+		WaitGroup sync.WaitGroup
+	}
+
+	t.Run("state", func(t *testing.T) {
+		if !mutexLayoutOK || !onceLayoutOK || !waitGroupLayoutOK {
+			t.Fatal("layout of sync values does not match the sync package")
+		}
+
+		in := new(S)
+		in.Once.Do(func() {})
+		in.WaitGroup.Add(2)
+
+		b, err := Serialize(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := Deserialize(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := v.(*S)
+
+		if !out.Mutex.TryLock() {
+			t.Error("mutex is locked after deserialization")
+		}
+		out.Once.Do(func() { t.Error("once ran again after deserialization") })
+
+		out.WaitGroup.Add(-1)
+		out.WaitGroup.Add(-1) // panics if the counter was not restored
+		out.WaitGroup.Wait()
+
+		in.WaitGroup.Add(-2)
+	})
+
+	t.Run("locked mutex", func(t *testing.T) {
+		in := new(S)
+		in.Mutex.Lock()
+		defer in.Mutex.Unlock()
+
+		defer func() {
+			err, _ := recover().(error)
+			if !errors.Is(err, errMutexLocked) {
+				t.Errorf("unexpected panic: got %v, expect %v", err, errMutexLocked)
+			}
+		}()
+		Serialize(in)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		Register[sync.Mutex](
+			func(s *Serializer, x *sync.Mutex) error { return nil },
+			func(d *Deserializer, x *sync.Mutex) error { return nil },
+		)
+		defer Register[sync.Mutex](serializeMutex, deserializeMutex)
+
+		in := new(S)
+		in.Mutex.Lock()
+		defer in.Mutex.Unlock()
+
+		if _, err := Serialize(in); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
 func TestReflectCustom(t *testing.T) {
 	ser := func(s *Serializer, x *int) error {
 		str := strconv.Itoa(*x)
@@ -471,6 +1116,51 @@ func TestReflectCustom(t *testing.T) {
 		}
 	})
 
+	testReflect(t, "custom type serialized as array", func(t *testing.T) {
+		type vec struct{ x, y, z int }
+
+		ser := func(s *Serializer, v *vec) error {
+			SerializeT(s, [3]int{v.x, v.y, v.z})
+			return nil
+		}
+		des := func(d *Deserializer, v *vec) error {
+			var a [3]int
+			DeserializeTo(d, &a)
+			v.x, v.y, v.z = a[0], a[1], a[2]
+			return nil
+		}
+		Register[vec](ser, des)
+
+		assertRoundTrip(t, vec{1, 2, 3})
+		assertRoundTrip(t, []vec{{1, 2, 3}, {4, 5, 6}})
+	})
+
+	testReflect(t, "custom type deserialized as array of another length", func(t *testing.T) {
+		type vec struct{ x, y int }
+
+		ser := func(s *Serializer, v *vec) error {
+			SerializeT(s, [2]int{v.x, v.y})
+			return nil
+		}
+		des := func(d *Deserializer, v *vec) error {
+			var a [3]int
+			DeserializeTo(d, &a)
+			return nil
+		}
+		Register[vec](ser, des)
+
+		b, err := Serialize(vec{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected deserialization to panic")
+			}
+		}()
+		Deserialize(b)
+	})
+
 	testReflect(t, "custom type of struct", func(t *testing.T) {
 		ser := func(s *Serializer, x *http.Client) error {
 			i := uint64(x.Timeout)
@@ -579,6 +1269,56 @@ func TestReflectSharing(t *testing.T) {
 		assertEqual(t, 11, out.s3[0])
 	})
 
+	testReflect(t, "slice capacity", func(t *testing.T) {
+		s := make([]int, 2, 8)
+		s[0], s[1] = 1, 2
+
+		out := assertRoundTrip(t, s)
+
+		assertEqual(t, 2, len(out))
+		assertEqual(t, 8, cap(out))
+
+		// append does not reallocate while there is spare capacity
+		grown := append(out, 3)
+		assertEqual(t, unsafe.Pointer(&out[0]), unsafe.Pointer(&grown[0]))
+	})
+
+	testReflect(t, "sub-slice of another slice", func(t *testing.T) {
+		type X struct {
+			a []int
+			b []int
+		}
+
+		a := make([]int, 10)
+		orig := X{a: a, b: a[2:5]}
+
+		out := assertRoundTrip(t, orig)
+
+		assertEqual(t, 3, len(out.b))
+		assertEqual(t, 8, cap(out.b))
+
+		// writes through b are visible in a
+		out.b[0] = 42
+		assertEqual(t, 42, out.a[2])
+
+		// the backing array is serialized once
+		b, err := Serialize(orig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state, err := Inspect(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arrays := 0
+		for i := 0; i < state.NumRegion(); i++ {
+			if fmt.Sprint(state.Region(i).Type()) == "[10]int" {
+				arrays++
+			}
+		}
+		assertEqual(t, 1, arrays)
+	})
+
 	testReflect(t, "slice backing array with set capacities", func(t *testing.T) {
 		data := make([]int, 10)
 		for i := range data {
@@ -670,6 +1410,47 @@ func TestReflectSharing(t *testing.T) {
 		assertEqual(t, out, out.z)
 	})
 
+	testReflect(t, "two structs pointing to each other", func(t *testing.T) {
+		type X struct {
+			v    int
+			next *X
+		}
+
+		a := &X{v: 1}
+		b := &X{v: 2, next: a}
+		a.next = b
+
+		out := assertRoundTrip(t, a)
+
+		assertEqual(t, 2, out.next.v)
+		assertEqual(t, out, out.next.next)
+	})
+
+	testReflect(t, "doubly linked list", func(t *testing.T) {
+		type node struct {
+			v          int
+			prev, next *node
+		}
+
+		// Circular list of three nodes.
+		nodes := []*node{{v: 1}, {v: 2}, {v: 3}}
+		for i, n := range nodes {
+			n.next = nodes[(i+1)%len(nodes)]
+			n.prev = nodes[(i+len(nodes)-1)%len(nodes)]
+		}
+
+		out := assertRoundTrip(t, nodes[0])
+
+		n := out
+		for i := 0; i < len(nodes); i++ {
+			assertEqual(t, nodes[i].v, n.v)
+			assertEqual(t, n, n.next.prev)
+			assertEqual(t, n, n.prev.next)
+			n = n.next
+		}
+		assertEqual(t, out, n)
+	})
+
 	testReflect(t, "nested struct fields", func(t *testing.T) {
 		type Z struct {
 			v int64
@@ -734,6 +1515,290 @@ func TestReflectSharing(t *testing.T) {
 		assertEqual(t, "test", out.y.z.v)
 	})
 
+	testReflect(t, "channels shared across structs", func(t *testing.T) {
+		type Struct struct {
+			ch chan int
+		}
+		type X struct {
+			a, b *Struct
+			recv <-chan int
+			none chan int
+		}
+
+		ch := make(chan int, 3)
+		x := X{a: &Struct{ch: ch}, b: &Struct{ch: ch}, recv: ch}
+
+		b, err := Serialize(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := Deserialize(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := v.(X)
+
+		if out.a.ch == nil {
+			t.Fatal("channel was not deserialized")
+		}
+		if out.a.ch == ch {
+			t.Error("deserialized channel is the original channel")
+		}
+		if out.a.ch != out.b.ch {
+			t.Error("channel identity was not preserved")
+		}
+		if (<-chan int)(out.a.ch) != out.recv {
+			t.Error("channel identity was not preserved across directions")
+		}
+		if out.none != nil {
+			t.Error("nil channel was not preserved")
+		}
+		assertEqual(t, 3, cap(out.a.ch))
+
+		out.a.ch <- 42
+		assertEqual(t, 42, <-out.recv)
+	})
+
+	testReflect(t, "channels with buffered values", func(t *testing.T) {
+		type item struct {
+			n int
+			p *int
+		}
+		n := 7
+
+		ch := make(chan item, 4)
+		// Wrap around the circular buffer of the channel.
+		ch <- item{n: 0}
+		ch <- item{n: 0}
+		<-ch
+		<-ch
+		ch <- item{n: 1}
+		ch <- item{n: 2, p: &n}
+		ch <- item{n: 3}
+
+		closed := make(chan string, 2)
+		closed <- "done"
+		close(closed)
+
+		type X struct {
+			ch     chan item
+			p      *int
+			closed chan string
+			any    any
+		}
+		x := X{ch: ch, p: &n, closed: closed, any: ch}
+
+		b, err := Serialize(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertCanInspect(t, b)
+
+		v, err := Deserialize(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := v.(X)
+
+		// The original channel must not be altered.
+		assertEqual(t, 3, len(ch))
+		assertEqual(t, 1, len(closed))
+
+		assertEqual(t, 4, cap(out.ch))
+		assertEqual(t, 3, len(out.ch))
+		if out.any.(chan item) != out.ch {
+			t.Error("channel identity was not preserved in interface")
+		}
+		for i := 1; i <= 3; i++ {
+			v := <-out.ch
+			assertEqual(t, i, v.n)
+			if i == 2 && v.p != out.p {
+				t.Error("pointer buffered in channel is not shared")
+			}
+		}
+
+		s, ok := <-out.closed
+		assertEqual(t, "done", s)
+		assertEqual(t, true, ok)
+		_, ok = <-out.closed
+		assertEqual(t, false, ok)
+	})
+
+	testReflect(t, "partly drained channel", func(t *testing.T) {
+		if !hchanLayoutOK {
+			t.Fatal("layout of channels does not match the Go runtime")
+		}
+
+		ch := make(chan int, 5)
+		for i := 1; i <= 4; i++ {
+			ch <- i
+		}
+		<-ch
+		<-ch
+
+		if c := (*hchan)(*(*unsafe.Pointer)(unsafe.Pointer(&ch))); c.recvx == 0 {
+			t.Fatalf("receive index of the channel is zero")
+		}
+
+		b, err := Serialize(ch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := Deserialize(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := v.(chan int)
+
+		assertEqual(t, 5, cap(out))
+		assertEqual(t, 2, len(out))
+		assertEqual(t, 3, <-out)
+		assertEqual(t, 4, <-out)
+	})
+
+	testReflect(t, "channel with unsupported layout", func(t *testing.T) {
+		defer func(ok bool) { hchanLayoutOK = ok }(hchanLayoutOK)
+		hchanLayoutOK = false
+
+		type X struct {
+			ch chan int
+		}
+		for _, v := range []any{make(chan int), X{ch: make(chan int, 1)}} {
+			if _, err := Serialize(v); !errors.Is(err, errChanLayout) {
+				t.Errorf("unexpected error serializing %T: %v", v, err)
+			}
+		}
+	})
+
+	testReflect(t, "slices sharing backing array across structs", func(t *testing.T) {
+		type Struct struct {
+			S []int
+		}
+
+		a := make([]int, 10)
+		for i := range a {
+			a[i] = i
+		}
+		s1 := &Struct{S: a[:5]}
+		s2 := &Struct{S: a[3:]}
+
+		type X struct {
+			s1 *Struct
+			s2 *Struct
+		}
+
+		out := assertRoundTrip(t, X{s1: s1, s2: s2})
+
+		assertEqual(t, []int{0, 1, 2, 3, 4}, out.s1.S)
+		assertEqual(t, []int{3, 4, 5, 6, 7, 8, 9}, out.s2.S)
+		assertEqual(t, 10, cap(out.s1.S))
+		assertEqual(t, 7, cap(out.s2.S))
+		assertEqual(t, unsafe.Pointer(&out.s1.S[3]), unsafe.Pointer(&out.s2.S[0]))
+
+		// verify the overlap is shared in both directions
+		out.s1.S[4] = 40
+		assertEqual(t, 40, out.s2.S[1])
+		out.s2.S[0] = 30
+		assertEqual(t, 30, out.s1.S[3])
+	})
+
+	testReflect(t, "slices sharing backing array across values in interfaces", func(t *testing.T) {
+		type Struct struct {
+			S []int
+		}
+
+		a := make([]int, 10)
+		s1 := Struct{S: a[:5]}
+		s2 := Struct{S: a[3:]}
+
+		out := assertRoundTrip(t, []any{s1, s2})
+
+		out1 := out[0].(Struct)
+		out2 := out[1].(Struct)
+		out1.S[3] = 42
+		assertEqual(t, 42, out2.S[0])
+	})
+
+	testReflect(t, "pointer into struct array field", func(t *testing.T) {
+		type X struct {
+			arr [8]int
+			p   *int
+		}
+
+		x := &X{}
+		for i := range x.arr {
+			x.arr[i] = i
+		}
+		x.p = &x.arr[3]
+		assertEqual(t, 3, *x.p)
+
+		out := assertRoundTrip(t, x)
+
+		assertEqual(t, unsafe.Pointer(&out.arr[3]), unsafe.Pointer(out.p))
+		out.arr[3] = 42
+		assertEqual(t, 42, *out.p)
+	})
+
+	testReflect(t, "pointer to struct map field", func(t *testing.T) {
+		type X struct {
+			m map[int]int
+			p *map[int]int
+		}
+
+		x := &X{m: map[int]int{1: 2}}
+		x.p = &x.m
+
+		out := assertRoundTrip(t, x)
+
+		assertEqual(t, unsafe.Pointer(&out.m), unsafe.Pointer(out.p))
+		(*out.p)[3] = 4
+		assertEqual(t, 4, out.m[3])
+	})
+
+	testReflect(t, "pointer to map", func(t *testing.T) {
+		m := map[int]int{1: 2}
+		x := &m
+
+		out := assertRoundTrip(t, x)
+		assertEqual(t, 2, (*out)[1])
+	})
+
+	testReflect(t, "pointer into array of structs", func(t *testing.T) {
+		type Y struct {
+			a, b int
+		}
+		type X struct {
+			p   *int
+			arr [4]Y
+		}
+
+		x := &X{}
+		x.p = &x.arr[2].b
+
+		out := assertRoundTrip(t, x)
+
+		assertEqual(t, unsafe.Pointer(&out.arr[2].b), unsafe.Pointer(out.p))
+		*out.p = 11
+		assertEqual(t, 11, out.arr[2].b)
+		assertEqual(t, 0, out.arr[2].a)
+	})
+
+	testReflect(t, "pointer into array held by another pointer", func(t *testing.T) {
+		type X struct {
+			elem *int
+			arr  *[5]int
+		}
+
+		arr := &[5]int{1, 2, 3, 4, 5}
+		x := X{elem: &arr[4], arr: arr}
+
+		out := assertRoundTrip(t, x)
+
+		assertEqual(t, unsafe.Pointer(&out.arr[4]), unsafe.Pointer(out.elem))
+		out.arr[4] = 50
+		assertEqual(t, 50, *out.elem)
+	})
+
 	testReflect(t, "slices with same backing array but no joined cap", func(t *testing.T) {
 		data := make([]int, 10)
 		for i := range data {
@@ -782,6 +1847,84 @@ func TestReflectSharing(t *testing.T) {
 
 		assertEqual(t, []int{100, 200, 300}, out["trois"])
 	})
+
+	testReflect(t, "maps with pointer keys and values", func(t *testing.T) {
+		type node struct {
+			ID    int
+			Peers map[*node]*node
+		}
+
+		a := &node{ID: 1}
+		b := &node{ID: 2}
+		a.Peers = map[*node]*node{a: b, b: a}
+		b.Peers = map[*node]*node{b: b}
+
+		type X struct {
+			A, B  *node
+			Index map[int]*node
+		}
+		x := X{A: a, B: b, Index: map[int]*node{1: a, 2: b}}
+
+		b1, err := Serialize(x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := Deserialize(b1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := v.(X)
+
+		if out.Index[1] != out.A || out.Index[2] != out.B {
+			t.Errorf("map values do not share pointers with struct fields")
+		}
+		if len(out.A.Peers) != 2 || out.A.Peers[out.A] != out.B || out.A.Peers[out.B] != out.A {
+			t.Errorf("map with pointer keys not restored: %v", out.A.Peers)
+		}
+		if len(out.B.Peers) != 1 || out.B.Peers[out.B] != out.B {
+			t.Errorf("map with pointer keys not restored: %v", out.B.Peers)
+		}
+		assertEqual(t, 1, out.A.ID)
+		assertEqual(t, 2, out.B.ID)
+	})
+
+	testReflect(t, "map of interfaces with heterogeneous values", func(t *testing.T) {
+		type point struct{ X, Y int }
+
+		n := 42
+		p := &point{X: 1, Y: 2}
+		x := map[string]any{
+			"int":     7,
+			"string":  "hello",
+			"slice":   []int{1, 2, 3},
+			"map":     map[string]int{"a": 1},
+			"struct":  point{X: 3, Y: 4},
+			"pointer": &n,
+			"p1":      p,
+			"p2":      p,
+			"nil":     nil,
+		}
+
+		out := assertRoundTrip(t, x)
+
+		assertEqual(t, 7, out["int"])
+		assertEqual(t, "hello", out["string"])
+		assertEqual(t, []int{1, 2, 3}, out["slice"])
+		assertEqual(t, map[string]int{"a": 1}, out["map"])
+		assertEqual(t, point{X: 3, Y: 4}, out["struct"])
+		assertEqual(t, 42, *out["pointer"].(*int))
+		if v, ok := out["nil"]; !ok || v != nil {
+			t.Errorf("nil value not preserved: %#v (present: %v)", v, ok)
+		}
+
+		// Pointers shared by multiple entries must still be shared.
+		p1, p2 := out["p1"].(*point), out["p2"].(*point)
+		if p1 != p2 {
+			t.Errorf("pointer sharing lost: %p != %p", p1, p2)
+		}
+		p1.X = 100
+		assertEqual(t, 100, p2.X)
+	})
 }
 
 func assertEqual(t *testing.T, expected, actual any) {
@@ -936,3 +2079,30 @@ func BenchmarkRoundtripString(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkRoundtripStructSlice(b *testing.B) {
+	type point struct {
+		X, Y, Z float64
+		Label   string
+		Visible bool
+	}
+
+	s := make([]point, 1000)
+	for i := range s {
+		s[i] = point{X: float64(i), Y: float64(2 * i), Z: float64(3 * i), Label: "p", Visible: i%2 == 0}
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		buf, err := Serialize(s)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = Deserialize(buf)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package types
+
+import "reflect"
+
+// Stats are aggregate metrics about a serialized state, as returned by
+// (*State).Stats.
+type Stats struct {
+	// Regions is the number of memory regions, excluding the root.
+	Regions int
+	// RegionBytes is the total size of the regions, excluding the root.
+	RegionBytes int64
+	// Kinds breaks down the regions by the kind of their type. Regions
+	// holding arrays (e.g. the backing array of a slice or the bytes of
+	// a string) are reported under reflect.Array.
+	Kinds map[reflect.Kind]KindStats
+	// OpaqueTypes is the number of types that had a custom serializer
+	// registered in the program that generated the state.
+	OpaqueTypes int
+	// Largest is the largest region, or nil if the state has no regions.
+	Largest *Region
+}
+
+// KindStats are the number and total size of regions of a kind.
+type KindStats struct {
+	Regions int
+	Bytes   int64
+}
+
+// Stats computes aggregate metrics about the types and memory regions of
+// the state, which help find what makes a serialized coroutine large.
+// Values are not deserialized.
+func (s *State) Stats() Stats {
+	stats := Stats{
+		Regions: s.NumRegion(),
+		Kinds:   make(map[reflect.Kind]KindStats),
+	}
+	for i := 0; i < s.NumRegion(); i++ {
+		r := s.Region(i)
+		size := r.Size()
+		stats.RegionBytes += size
+
+		kind := r.Type().Kind()
+		k := stats.Kinds[kind]
+		k.Regions++
+		k.Bytes += size
+		stats.Kinds[kind] = k
+
+		if stats.Largest == nil || size > stats.Largest.Size() {
+			stats.Largest = r
+		}
+	}
+	for i := 0; i < s.NumType(); i++ {
+		if s.Type(i).Opaque() {
+			stats.OpaqueTypes++
+		}
+	}
+	return stats
+}
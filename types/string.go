@@ -17,6 +17,12 @@ func newStringMap(strings []string) *stringmap {
 	}
 }
 
+func (m *stringmap) reset() {
+	clear(m.strings)
+	m.strings = m.strings[:0]
+	clear(m.seen)
+}
+
 func (m *stringmap) Intern(s string) stringid {
 	if s == "" {
 		return 0
@@ -14,8 +14,16 @@ type typemap struct {
 	serdes  *serdemap
 	strings *stringmap
 
-	types []*coroutinev1.Type
-	cache doublemap[typeid, reflect.Type]
+	types  []*coroutinev1.Type
+	cache  doublemap[typeid, reflect.Type]
+	fields map[reflect.Type][]structField
+}
+
+// structField is the subset of reflect.StructField needed to serialize,
+// deserialize and scan the fields of a struct.
+type structField struct {
+	typ    reflect.Type
+	offset uintptr
 }
 
 func newTypeMap(serdes *serdemap, strings *stringmap, types []*coroutinev1.Type) *typemap {
@@ -32,6 +40,32 @@ func (m *typemap) register(t *coroutinev1.Type) typeid {
 	return id
 }
 
+// reset clears the types registered in m so it can be reused. Memoized
+// struct layouts are kept since they do not depend on the state.
+func (m *typemap) reset() {
+	clear(m.types)
+	m.types = m.types[:0]
+	m.cache.reset()
+}
+
+// structFields returns the fields of the struct type t. The result is
+// memoized, since reflect.Type.Field allocates each time it is called.
+func (m *typemap) structFields(t reflect.Type) []structField {
+	if fields, ok := m.fields[t]; ok {
+		return fields
+	}
+	fields := make([]structField, t.NumField())
+	for i := range fields {
+		f := t.Field(i)
+		fields[i] = structField{typ: f.Type, offset: f.Offset}
+	}
+	if m.fields == nil {
+		m.fields = make(map[reflect.Type][]structField)
+	}
+	m.fields[t] = fields
+	return fields
+}
+
 func (m *typemap) lookup(id typeid) *coroutinev1.Type {
 	if id == 0 || id > uint32(len(m.types)) {
 		return nil
@@ -371,6 +405,12 @@ func (m *funcmap) register(f *coroutinev1.Function) typeid {
 	return id
 }
 
+func (m *funcmap) reset() {
+	clear(m.funcs)
+	m.funcs = m.funcs[:0]
+	m.cache.reset()
+}
+
 func (m *funcmap) lookup(id funcid) *coroutinev1.Function {
 	if id == 0 || id > uint32(len(m.funcs)) {
 		return nil
@@ -429,6 +469,11 @@ func (m *doublemap[K, V]) getV(v V) (K, bool) {
 	return k, ok
 }
 
+func (m *doublemap[K, V]) reset() {
+	clear(m.fromK)
+	clear(m.fromV)
+}
+
 func (m *doublemap[K, V]) add(k K, v V) V {
 	if m.fromK == nil {
 		m.fromK = make(map[K]V)
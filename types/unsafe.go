@@ -34,6 +34,8 @@ func inlined(t reflect.Type) bool {
 		return true
 	case reflect.Map:
 		return true
+	case reflect.Chan:
+		return true
 	case reflect.Struct:
 		return t.NumField() == 1 && inlined(t.Field(0).Type)
 	case reflect.Array: